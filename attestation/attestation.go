@@ -0,0 +1,197 @@
+// Package attestation signs and verifies prices published by the price fetcher.
+//
+// Every quote of a published update is signed on its own with ed25519. The signed message
+// is built from the asset, the quote currency, the exact decimal price, the unix timestamp
+// in seconds and the round, see Payload.Bytes. Websocket frames carry the signatures of
+// their quotes in `signatures` and the signing key in `key_id`, VerifyFrame checks them all.
+// gRPC clients build a Payload from the prices, signatures, time_date and round fields
+// of PricesResponse and call Verify. The public key is served on /pubkey.
+//
+// Stored prices are periodically sealed into batches committed to by a Merkle root, see
+// PriceLeaf. InclusionProof, served on /proof and by the GetPriceProof rpc, lets auditors
+// check that replayed history matches what was sealed.
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Domain separates price attestations from anything else signed with the same key.
+const Domain = "pricefetcher/price/v1"
+
+// maxScale bounds the digits after the decimal point of a signed price.
+const maxScale = 100
+
+var ErrInvalidSignature = errors.New("invalid price signature")
+
+// Payload is the content covered by a signature.
+type Payload struct {
+	Asset string
+	Quote string
+	// Price is the decimal price, it is canonicalized so "41234.50" and "41234.5" sign the same.
+	Price     string
+	Timestamp int64
+	Round     uint64
+}
+
+// Bytes returns the canonical encoding of p, one `name=value` line per field after Domain.
+func (p Payload) Bytes() ([]byte, error) {
+	price, err := canonicalDecimal(p.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(Domain)
+	b.WriteString("\nasset=")
+	b.WriteString(strings.ToUpper(p.Asset))
+	b.WriteString("\nquote=")
+	b.WriteString(strings.ToUpper(p.Quote))
+	b.WriteString("\nprice=")
+	b.WriteString(price)
+	b.WriteString("\ntimestamp=")
+	b.WriteString(strconv.FormatInt(p.Timestamp, 10))
+	b.WriteString("\nround=")
+	b.WriteString(strconv.FormatUint(p.Round, 10))
+	return []byte(b.String()), nil
+}
+
+// Signer signs payloads with a single ed25519 key.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}
+}
+
+// Sign returns the base64 encoded signature of p.
+func (s *Signer) Sign(p Payload) (string, error) {
+	msg, err := p.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, msg)), nil
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Verify checks the base64 encoded signature of p.
+func Verify(key ed25519.PublicKey, p Payload, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("wrong signature encoding: %w", err)
+	}
+	msg, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// KeyID identifies key by the first 8 bytes of its SHA-256, hex encoded.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParsePrivateKey reads a PKCS#8 PEM key as written by `openssl genpkey -algorithm ed25519`,
+// or a base64 encoded 32 byte seed or 64 byte private key.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ed, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("key is %T, not ed25519", key)
+		}
+		return ed, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("wrong key encoding: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+	return nil, fmt.Errorf("wrong key length %d", len(raw))
+}
+
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKey(data)
+}
+
+// ParsePublicKey reads a PKIX PEM key or a base64 encoded 32 byte key as served on /pubkey.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ed, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key is %T, not ed25519", key)
+		}
+		return ed, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("wrong key encoding: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("wrong key length %d", len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// canonicalDecimal formats a decimal without exponent, leading or trailing zeros.
+func canonicalDecimal(s string) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return "", fmt.Errorf("wrong price %q", s)
+	}
+
+	// the scale of a decimal is the smallest power of ten its denominator divides
+	scale := 0
+	pow := big.NewInt(1)
+	for new(big.Int).Mod(pow, r.Denom()).Sign() != 0 {
+		if scale == maxScale {
+			return "", fmt.Errorf("price %q is not a decimal", s)
+		}
+		pow.Mul(pow, big.NewInt(10))
+		scale++
+	}
+	return r.FloatString(scale), nil
+}
@@ -0,0 +1,69 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func TestSignVerify(t *testing.T) {
+	signer := NewSigner(testKey(t))
+	payload := Payload{Asset: "btc", Quote: "usd", Price: "41234.50", Timestamp: 1706015736, Round: 7}
+
+	signature, err := signer.Sign(payload)
+	require.NoError(t, err)
+	require.NoError(t, Verify(signer.PublicKey(), payload, signature))
+
+	// the same price written differently is the same attestation
+	payload.Price = "4.12345E+4"
+	payload.Asset = "BTC"
+	require.NoError(t, Verify(signer.PublicKey(), payload, signature))
+
+	for _, tampered := range []Payload{
+		{Asset: "ETH", Quote: "USD", Price: "41234.5", Timestamp: 1706015736, Round: 7},
+		{Asset: "BTC", Quote: "EUR", Price: "41234.5", Timestamp: 1706015736, Round: 7},
+		{Asset: "BTC", Quote: "USD", Price: "41234.51", Timestamp: 1706015736, Round: 7},
+		{Asset: "BTC", Quote: "USD", Price: "41234.5", Timestamp: 1706015737, Round: 7},
+		{Asset: "BTC", Quote: "USD", Price: "41234.5", Timestamp: 1706015736, Round: 8},
+	} {
+		require.ErrorIs(t, Verify(signer.PublicKey(), tampered, signature), ErrInvalidSignature)
+	}
+
+	other := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	require.ErrorIs(t, Verify(other.Public().(ed25519.PublicKey), payload, signature), ErrInvalidSignature)
+	require.Error(t, Verify(signer.PublicKey(), Payload{Price: "1/3"}, signature))
+}
+
+func TestParseKeys(t *testing.T) {
+	key := testKey(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	parsed, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	require.Equal(t, key, parsed)
+
+	parsed, err = ParsePrivateKey([]byte(base64.StdEncoding.EncodeToString(key.Seed()) + "\n"))
+	require.NoError(t, err)
+	require.Equal(t, key, parsed)
+
+	_, err = ParsePrivateKey([]byte("c2hvcnQ="))
+	require.Error(t, err)
+
+	public, err := ParsePublicKey([]byte(base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))))
+	require.NoError(t, err)
+	require.Equal(t, KeyID(key.Public().(ed25519.PublicKey)), KeyID(public))
+	require.Len(t, KeyID(public), 16)
+}
@@ -0,0 +1,62 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VerifyFrame checks every signature of a websocket price frame and returns the verified quote currencies.
+// Frames signed with another key than key are rejected.
+func VerifyFrame(key ed25519.PublicKey, frame []byte) ([]string, error) {
+	var fields map[string]json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(frame))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		TimeDate   time.Time         `json:"timedate"`
+		Asset      string            `json:"asset"`
+		Round      uint64            `json:"round"`
+		KeyID      string            `json:"key_id"`
+		Signatures map[string]string `json:"signatures"`
+	}
+	if err := json.Unmarshal(frame, &header); err != nil {
+		return nil, err
+	}
+	if len(header.Signatures) == 0 {
+		return nil, fmt.Errorf("frame is not signed")
+	}
+	if header.KeyID != KeyID(key) {
+		return nil, fmt.Errorf("frame is signed with key %s, expected %s", header.KeyID, KeyID(key))
+	}
+
+	var verified []string
+	for quote, signature := range header.Signatures {
+		raw, ok := fields["price_"+strings.ToLower(quote)]
+		if !ok && strings.EqualFold(quote, "USD") {
+			// frames without a requested USD quote carry it as price
+			raw, ok = fields["price"]
+		}
+		if !ok {
+			return nil, fmt.Errorf("signed %s price is missing", quote)
+		}
+		payload := Payload{
+			Asset:     header.Asset,
+			Quote:     quote,
+			Price:     string(raw),
+			Timestamp: header.TimeDate.Unix(),
+			Round:     header.Round,
+		}
+		if err := Verify(key, payload, signature); err != nil {
+			return nil, fmt.Errorf("%s: %w", quote, err)
+		}
+		verified = append(verified, quote)
+	}
+	return verified, nil
+}
@@ -0,0 +1,186 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Leaves and nodes are hashed with different prefixes so a node can't be passed off as a leaf.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var ErrInvalidProof = errors.New("leaf is not committed to by the root")
+
+// PriceLeaf is a stored tick as committed to by a batch.
+type PriceLeaf struct {
+	Asset     string            `json:"asset"`
+	Timestamp int64             `json:"timestamp"`
+	Round     uint64            `json:"round,omitempty"`
+	Prices    map[string]string `json:"prices"`
+}
+
+// Bytes returns the canonical encoding of l, prices are sorted by currency.
+func (l PriceLeaf) Bytes() ([]byte, error) {
+	codes := make([]string, 0, len(l.Prices))
+	for code := range l.Prices {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var b strings.Builder
+	b.WriteString("pricefetcher/leaf/v1\nasset=")
+	b.WriteString(strings.ToUpper(l.Asset))
+	b.WriteString("\ntimestamp=")
+	b.WriteString(strconv.FormatInt(l.Timestamp, 10))
+	b.WriteString("\nround=")
+	b.WriteString(strconv.FormatUint(l.Round, 10))
+	for _, code := range codes {
+		price, err := canonicalDecimal(l.Prices[code])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", code, err)
+		}
+		b.WriteString("\n")
+		b.WriteString(strings.ToUpper(code))
+		b.WriteString("=")
+		b.WriteString(price)
+	}
+	return []byte(b.String()), nil
+}
+
+// Hash returns the Merkle leaf hash of l.
+func (l PriceLeaf) Hash() ([]byte, error) {
+	data, err := l.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return LeafHash(data), nil
+}
+
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Hash is a SHA-256 digest, hex encoded in JSON.
+type Hash []byte
+
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*h = decoded
+	return nil
+}
+
+// ProofStep is a sibling hash on the path from a leaf to the root, Left tells on which side it goes.
+type ProofStep struct {
+	Hash Hash `json:"hash"`
+	Left bool `json:"left"`
+}
+
+// InclusionProof shows that Leaf is committed to by the Merkle Root of batch Batch.
+type InclusionProof struct {
+	Batch     int64       `json:"batch"`
+	Root      Hash        `json:"root"`
+	SealedAt  int64       `json:"sealed_at"`
+	LeafIndex int         `json:"leaf_index"`
+	LeafCount int         `json:"leaf_count"`
+	Leaf      PriceLeaf   `json:"leaf"`
+	Path      []ProofStep `json:"path"`
+}
+
+// Verify checks the proof against its own root, auditors also compare Root with the one they recorded.
+func (p *InclusionProof) Verify() error {
+	leaf, err := p.Leaf.Hash()
+	if err != nil {
+		return err
+	}
+	if !VerifyProof(leaf, p.Path, p.Root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// MerkleRoot hashes leaf hashes pairwise up to the root, an unpaired last node moves up unchanged.
+func MerkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	level := leaves
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+// MerkleProof returns the inclusion proof of leaves[index].
+func MerkleProof(leaves [][]byte, index int) ([]ProofStep, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf %d out of %d", index, len(leaves))
+	}
+
+	var proof []ProofStep
+	level := leaves
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, ProofStep{Hash: level[sibling], Left: sibling < index})
+		}
+		level = nextLevel(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyProof checks that leaf is committed to by root.
+func VerifyProof(leaf []byte, proof []ProofStep, root []byte) bool {
+	hash := leaf
+	for _, step := range proof {
+		if step.Left {
+			hash = nodeHash(step.Hash, hash)
+		} else {
+			hash = nodeHash(hash, step.Hash)
+		}
+	}
+	return bytes.Equal(hash, root)
+}
+
+func nextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, nodeHash(level[i], level[i+1]))
+	}
+	return next
+}
@@ -0,0 +1,60 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		var leaves [][]byte
+		for i := 0; i < n; i++ {
+			leaves = append(leaves, LeafHash([]byte(fmt.Sprintf("leaf %d", i))))
+		}
+		root := MerkleRoot(leaves)
+
+		for i := range leaves {
+			proof, err := MerkleProof(leaves, i)
+			require.NoError(t, err)
+			require.True(t, VerifyProof(leaves[i], proof, root), "leaf %d of %d", i, n)
+			require.False(t, VerifyProof(LeafHash([]byte("forged")), proof, root))
+		}
+	}
+
+	_, err := MerkleProof([][]byte{LeafHash(nil)}, 1)
+	require.Error(t, err)
+	require.Nil(t, MerkleRoot(nil))
+}
+
+func TestInclusionProof(t *testing.T) {
+	leaves := []PriceLeaf{
+		{Asset: "BTC", Timestamp: 1706015736, Round: 1, Prices: map[string]string{"USD": "41234.5", "EUR": "37800.25"}},
+		{Asset: "ETH", Timestamp: 1706015736, Prices: map[string]string{"USD": "2000"}},
+		{Asset: "BTC", Timestamp: 1706015741, Round: 2, Prices: map[string]string{"USD": "41240"}},
+	}
+	var hashes [][]byte
+	for _, leaf := range leaves {
+		hash, err := leaf.Hash()
+		require.NoError(t, err)
+		hashes = append(hashes, hash)
+	}
+	path, err := MerkleProof(hashes, 2)
+	require.NoError(t, err)
+
+	proof := InclusionProof{Batch: 1, Root: MerkleRoot(hashes), LeafIndex: 2, LeafCount: 3, Leaf: leaves[2], Path: path}
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+
+	var decoded InclusionProof
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, decoded.Verify())
+
+	// replayed history has to match the sealed leaf exactly, formatting aside
+	decoded.Leaf.Prices["USD"] = "41240.00"
+	require.NoError(t, decoded.Verify())
+	decoded.Leaf.Prices["USD"] = "41241"
+	require.ErrorIs(t, decoded.Verify(), ErrInvalidProof)
+}
@@ -0,0 +1,67 @@
+// Package attest signs prices flowing through the pipeline, see the attestation package.
+package attest
+
+import (
+	"context"
+
+	"code.injective.org/service/pricefetcher/attestation"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+type Attester struct {
+	signer *attestation.Signer
+}
+
+func NewAttester(signer *attestation.Signer) *Attester {
+	return &Attester{signer: signer}
+}
+
+func (a *Attester) Signer() *attestation.Signer {
+	return a.signer
+}
+
+// Apply returns a copy of price with every rate signed, the asset is always set since it is signed.
+func (a *Attester) Apply(price *model.CurrentPrice) *model.CurrentPrice {
+	res := *price
+	res.Asset = price.AssetSymbol()
+	res.KeyID = a.signer.KeyID()
+	res.Signatures = make(map[string]string, len(price.Bpi))
+	for code, rate := range price.Bpi {
+		signature, err := a.signer.Sign(Payload(&res, code, rate))
+		if err != nil {
+			log.Err(err).Msgf("failed to sign %s/%s price", res.Asset, code)
+			continue
+		}
+		res.Signatures[code] = signature
+	}
+	return &res
+}
+
+// Run signs every price read from in and forwards it to out until ctx is done.
+func (a *Attester) Run(ctx context.Context, in <-chan *model.CurrentPrice, out chan<- *model.CurrentPrice) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case price := <-in:
+			select {
+			case out <- a.Apply(price):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Payload returns what is signed for the rate of price quoted in code.
+func Payload(price *model.CurrentPrice, code string, rate model.CurrentPriceRate) attestation.Payload {
+	return attestation.Payload{
+		Asset:     price.AssetSymbol(),
+		Quote:     code,
+		Price:     rate.Value.String(),
+		Timestamp: price.Time.UpdatedISO.Unix(),
+		Round:     price.Round,
+	}
+}
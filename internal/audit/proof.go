@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"code.injective.org/service/pricefetcher/attestation"
+	"code.injective.org/service/pricefetcher/internal/repository"
+)
+
+type Prover struct {
+	batches repository.Batches
+}
+
+func NewProver(batches repository.Batches) *Prover {
+	return &Prover{batches: batches}
+}
+
+// Proof returns the inclusion proof of the price stored under seq, as several prices of an asset may share a second.
+// repository.ErrNotFound is returned for unknown prices and ErrNotSealed for recent ones.
+func (p *Prover) Proof(ctx context.Context, seq uint64) (*attestation.InclusionProof, error) {
+	price, err := p.batches.FindPrice(ctx, seq)
+	if err != nil {
+		return nil, err
+	}
+	if price.Batch == 0 {
+		return nil, ErrNotSealed
+	}
+
+	batch, err := p.batches.Get(ctx, price.Batch)
+	if errors.Is(err, repository.ErrNotFound) {
+		// prices are marked before their batch is stored
+		return nil, ErrNotSealed
+	}
+	if err != nil {
+		return nil, err
+	}
+	prices, err := p.batches.Prices(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	leaves := make([][]byte, 0, len(prices))
+	for i, stored := range prices {
+		hash, err := Leaf(&stored).Hash()
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, hash)
+		if stored.ID == price.ID {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("price %s isn't part of batch %d", price.ID.Hex(), batch.ID)
+	}
+
+	path, err := attestation.MerkleProof(leaves, index)
+	if err != nil {
+		return nil, err
+	}
+	root, err := hex.DecodeString(batch.Root)
+	if err != nil {
+		return nil, fmt.Errorf("wrong root of batch %d: %w", batch.ID, err)
+	}
+	return &attestation.InclusionProof{
+		Batch:     batch.ID,
+		Root:      root,
+		SealedAt:  batch.SealedAt,
+		LeafIndex: index,
+		LeafCount: len(leaves),
+		Leaf:      Leaf(price),
+		Path:      path,
+	}, nil
+}
@@ -0,0 +1,106 @@
+// Package audit seals stored prices into Merkle batches and serves inclusion proofs,
+// see attestation.PriceLeaf for what a batch commits to.
+package audit
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.injective.org/service/pricefetcher/attestation"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNotSealed = errors.New("price is not sealed yet")
+
+type Sealer struct {
+	batches   repository.Batches
+	batchSize int
+}
+
+func NewSealer(batches repository.Batches, batchSize int) *Sealer {
+	return &Sealer{batches: batches, batchSize: batchSize}
+}
+
+// Run seals unsealed prices every interval until ctx is done.
+func (s *Sealer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case <-ticker.C:
+			// a backlog is sealed in several batches of batchSize
+			for {
+				batch, err := s.Seal(ctx)
+				if err != nil {
+					log.Err(err).Msg("error sealing prices")
+					break
+				}
+				if batch == nil || len(batch.PriceIDs) < s.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Seal commits to up to batchSize unsealed prices, nil is returned when there is nothing to seal.
+func (s *Sealer) Seal(ctx context.Context) (*model.PriceBatch, error) {
+	prices, err := s.batches.Unsealed(ctx, s.batchSize)
+	if err != nil || len(prices) == 0 {
+		return nil, err
+	}
+
+	batch := &model.PriceBatch{
+		From:     prices[0].CreatedAt,
+		To:       prices[0].CreatedAt,
+		SealedAt: time.Now().UTC().Unix(),
+	}
+	leaves := make([][]byte, 0, len(prices))
+	for _, price := range prices {
+		hash, err := Leaf(&price).Hash()
+		if err != nil {
+			return nil, fmt.Errorf("price %s: %w", price.ID.Hex(), err)
+		}
+		leaves = append(leaves, hash)
+		batch.PriceIDs = append(batch.PriceIDs, price.ID)
+		if price.CreatedAt < batch.From {
+			batch.From = price.CreatedAt
+		}
+		if price.CreatedAt > batch.To {
+			batch.To = price.CreatedAt
+		}
+	}
+	batch.Root = hex.EncodeToString(attestation.MerkleRoot(leaves))
+
+	if err = s.batches.Seal(ctx, batch); err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("sealed batch %d of %d prices with root %s", batch.ID, len(leaves), batch.Root)
+	return batch, nil
+}
+
+// Leaf returns what a batch commits to for a stored price.
+func Leaf(price *model.Prices) attestation.PriceLeaf {
+	asset := price.Asset
+	if asset == "" {
+		asset = model.DefaultAsset
+	}
+	leaf := attestation.PriceLeaf{
+		Asset:     asset,
+		Timestamp: price.CreatedAt,
+		Round:     price.Round,
+		Prices:    make(map[string]string, len(price.Price.Bpi)),
+	}
+	for code, rate := range price.Price.Bpi {
+		leaf.Prices[code] = rate.Value.String()
+	}
+	return leaf
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func storedPrice(asset string, createdAt int64, usd string) model.Prices {
+	return model.Prices{
+		ID:        primitive.NewObjectID(),
+		Asset:     asset,
+		CreatedAt: createdAt,
+		Price: model.PricesInfo{Bpi: map[string]model.PricesCurrentPriceRate{
+			"USD": {Code: "USD", Value: model.MustParseDecimal(usd)},
+		}},
+	}
+}
+
+func TestSealAndProve(t *testing.T) {
+	ctx := context.Background()
+	prices := []model.Prices{
+		storedPrice("BTC", 1706015736, "41234.5"),
+		storedPrice("", 1706015741, "41240"),
+		storedPrice("ETH", 1706015741, "2000.01"),
+	}
+
+	batches := mockRepo.NewMockBatches(t)
+	batches.On("Unsealed", mock.Anything, 10).Return(prices, nil).Once()
+	var sealed *model.PriceBatch
+	batches.On("Seal", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sealed = args.Get(1).(*model.PriceBatch)
+		sealed.ID = 7
+	}).Return(nil).Once()
+
+	batch, err := NewSealer(batches, 10).Seal(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sealed, batch)
+	require.Len(t, batch.PriceIDs, 3)
+	require.Equal(t, int64(1706015736), batch.From)
+	require.Equal(t, int64(1706015741), batch.To)
+
+	legacy := prices[1]
+	legacy.Batch = batch.ID
+	batches.On("FindPrice", mock.Anything, uint64(2)).Return(&legacy, nil).Once()
+	batches.On("Get", mock.Anything, int64(7)).Return(batch, nil).Once()
+	batches.On("Prices", mock.Anything, batch).Return(prices, nil).Once()
+
+	proof, err := NewProver(batches).Proof(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, proof.LeafIndex)
+	require.Equal(t, "BTC", proof.Leaf.Asset)
+	require.Equal(t, "41240", proof.Leaf.Prices["USD"])
+	require.NoError(t, proof.Verify())
+}
+
+func TestProveUnsealed(t *testing.T) {
+	batches := mockRepo.NewMockBatches(t)
+	price := storedPrice("BTC", 1706015736, "41234.5")
+	batches.On("FindPrice", mock.Anything, uint64(1)).Return(&price, nil).Once()
+	batches.On("FindPrice", mock.Anything, uint64(2)).Return(nil, repository.ErrNotFound).Once()
+
+	_, err := NewProver(batches).Proof(context.Background(), 1)
+	require.ErrorIs(t, err, ErrNotSealed)
+	_, err = NewProver(batches).Proof(context.Background(), 2)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// marked for a batch which isn't stored yet
+	price.Batch = 8
+	batches.On("FindPrice", mock.Anything, uint64(1)).Return(&price, nil).Once()
+	batches.On("Get", mock.Anything, int64(8)).Return(nil, repository.ErrNotFound).Once()
+	_, err = NewProver(batches).Proof(context.Background(), 1)
+	require.ErrorIs(t, err, ErrNotSealed)
+}
+
+func TestSealNothing(t *testing.T) {
+	batches := mockRepo.NewMockBatches(t)
+	batches.On("Unsealed", mock.Anything, 10).Return(nil, nil).Once()
+
+	batch, err := NewSealer(batches, 10).Seal(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, batch)
+}
@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// bufferSize is how many messages a subscriber may fall behind before messages are dropped.
+	bufferSize = 100
+
+	// AllPrices matches the price topics of every asset.
+	AllPrices = "prices.*"
+)
+
+var ErrClosed = errors.New("broker is closed")
+
+// Broker carries messages between instances grouped by topic, like NATS subjects or Redis channels.
+type Broker interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe delivers the messages of topics matching pattern until ctx is done, then closes the channel.
+	// Patterns are globs, "*" matching any sequence of characters.
+	Subscribe(ctx context.Context, pattern string) (<-chan []byte, error)
+	Close() error
+}
+
+// PriceTopic is the topic prices of asset are published on, e.g. "prices.BTC".
+func PriceTopic(asset string) string {
+	return "prices." + strings.ToUpper(asset)
+}
+
+// PublishPrices publishes every price read from in as JSON on the topic of its asset until in is closed.
+func PublishPrices(ctx context.Context, b Broker, in <-chan *model.CurrentPrice, errors chan<- error) {
+	for price := range in {
+		payload, err := json.Marshal(price)
+		if err != nil {
+			log.Err(err).Msg("error marshaling structure")
+			continue
+		}
+		if err = b.Publish(ctx, PriceTopic(price.AssetSymbol()), payload); err != nil {
+			log.Err(err).Msgf("failed to publish %s price", price.AssetSymbol())
+			errors <- err
+		}
+	}
+}
+
+// ReceivePrices decodes the prices of a subscription to price topics into out until ctx is done.
+func ReceivePrices(ctx context.Context, messages <-chan []byte, out chan<- *model.CurrentPrice) error {
+	for payload := range messages {
+		var price model.CurrentPrice
+		if err := json.Unmarshal(payload, &price); err != nil {
+			log.Err(err).Msg("skipping undecodable price message")
+			continue
+		}
+		select {
+		case out <- &price:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
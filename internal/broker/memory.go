@@ -0,0 +1,85 @@
+package broker
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+type memorySubscriber struct {
+	pattern string
+	queue   chan []byte
+}
+
+// Memory is a Broker for the instances of a single process.
+type Memory struct {
+	mutex       sync.RWMutex
+	subscribers map[*memorySubscriber]struct{}
+	closed      bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		subscribers: make(map[*memorySubscriber]struct{}),
+	}
+}
+
+func (m *Memory) Publish(_ context.Context, topic string, payload []byte) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.closed {
+		return ErrClosed
+	}
+
+	for sub := range m.subscribers {
+		if ok, _ := path.Match(sub.pattern, topic); !ok {
+			continue
+		}
+		// a slow subscriber misses messages instead of blocking the publisher
+		select {
+		case sub.queue <- payload:
+		default:
+			log.Warn().Msgf("subscriber of %s is too slow, dropping message on %s", sub.pattern, topic)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(ctx context.Context, pattern string) (<-chan []byte, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed {
+		return nil, ErrClosed
+	}
+	sub := &memorySubscriber{pattern: pattern, queue: make(chan []byte, bufferSize)}
+	m.subscribers[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if _, ok := m.subscribers[sub]; ok {
+			delete(m.subscribers, sub)
+			close(sub.queue)
+		}
+	}()
+	return sub.queue, nil
+}
+
+// Close ends every subscription.
+func (m *Memory) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closed = true
+	for sub := range m.subscribers {
+		delete(m.subscribers, sub)
+		close(sub.queue)
+	}
+	return nil
+}
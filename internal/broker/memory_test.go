@@ -0,0 +1,100 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, messages <-chan []byte) []byte {
+	select {
+	case payload, ok := <-messages:
+		require.True(t, ok, "subscription was closed")
+		return payload
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't delivered")
+	}
+	return nil
+}
+
+func TestMemoryRoutesByTopic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewMemory()
+
+	all, err := b.Subscribe(ctx, AllPrices)
+	require.NoError(t, err)
+	eth, err := b.Subscribe(ctx, PriceTopic("eth"))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(ctx, PriceTopic("BTC"), []byte("btc")))
+	require.NoError(t, b.Publish(ctx, PriceTopic("ETH"), []byte("eth")))
+	require.NoError(t, b.Publish(ctx, "candles.ETH", []byte("candle")))
+
+	require.Equal(t, "btc", string(receive(t, all)))
+	require.Equal(t, "eth", string(receive(t, all)))
+	require.Equal(t, "eth", string(receive(t, eth)))
+	require.Empty(t, all)
+	require.Empty(t, eth)
+
+	_, err = b.Subscribe(ctx, "prices.[")
+	require.Error(t, err)
+}
+
+func TestMemorySubscriptionEndsWithContext(t *testing.T) {
+	b := NewMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := b.Subscribe(ctx, AllPrices)
+	require.NoError(t, err)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-messages:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, b.Close())
+	require.ErrorIs(t, b.Publish(context.Background(), PriceTopic("BTC"), nil), ErrClosed)
+}
+
+func TestPricesRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewMemory()
+
+	messages, err := b.Subscribe(ctx, AllPrices)
+	require.NoError(t, err)
+	received := make(chan *model.CurrentPrice)
+	stopped := make(chan error)
+	go func() {
+		stopped <- ReceivePrices(ctx, messages, received)
+	}()
+
+	in := make(chan *model.CurrentPrice, 1)
+	price := &model.CurrentPrice{
+		Asset: "ETH",
+		Round: 3,
+		Time:  model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0).UTC()},
+		Bpi:   map[string]model.CurrentPriceRate{"USD": {Code: "USD", Value: model.MustParseDecimal("2250.123456789")}},
+	}
+	in <- price
+	close(in)
+	PublishPrices(ctx, b, in, make(chan error))
+
+	select {
+	case got := <-received:
+		require.Equal(t, price, got)
+	case <-time.After(time.Second):
+		t.Fatal("price wasn't delivered")
+	}
+
+	cancel()
+	require.ErrorIs(t, <-stopped, context.Canceled)
+}
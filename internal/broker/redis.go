@@ -0,0 +1,76 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const redisDialTimeout = 5 * time.Second
+
+// Redis is a Broker on Redis pub/sub, or on anything speaking its protocol.
+// Messages published while a subscriber reconnects are lost, like with any pub/sub.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(addr string) *Redis {
+	return &Redis{client: redis.NewClient(&redis.Options{Addr: addr, DialTimeout: redisDialTimeout})}
+}
+
+func (r *Redis) Publish(ctx context.Context, topic string, payload []byte) error {
+	err := r.client.Publish(ctx, topic, payload).Err()
+	if errors.Is(err, redis.ErrClosed) {
+		return ErrClosed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish on %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe connects before returning so a broker that can't be reached is reported,
+// the subscription reconnects on its own afterwards.
+func (r *Redis) Subscribe(ctx context.Context, pattern string) (<-chan []byte, error) {
+	sub := r.client.PSubscribe(ctx, pattern)
+	// the first reply confirms the subscription
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		if errors.Is(err, redis.ErrClosed) {
+			return nil, ErrClosed
+		}
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", pattern, err)
+	}
+
+	out := make(chan []byte, bufferSize)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(message.Payload):
+				default:
+					log.Warn().Msgf("subscriber of %s is too slow, dropping message", pattern)
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Close closes the connections publishing goes through, subscriptions end with their context.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
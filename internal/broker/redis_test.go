@@ -0,0 +1,65 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisPublishSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := miniredis.RunT(t)
+	b := NewRedis(server.Addr())
+	defer b.Close()
+
+	all, err := b.Subscribe(ctx, AllPrices)
+	require.NoError(t, err)
+	btc, err := b.Subscribe(ctx, PriceTopic("btc"))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(ctx, PriceTopic("BTC"), []byte("btc\r\nprice")))
+	require.NoError(t, b.Publish(ctx, PriceTopic("ETH"), []byte("eth")))
+
+	require.Equal(t, "btc\r\nprice", string(receive(t, all)))
+	require.Equal(t, "eth", string(receive(t, all)))
+	require.Equal(t, "btc\r\nprice", string(receive(t, btc)))
+
+	cancel()
+	require.Eventually(t, func() bool { return server.PubSubNumPat() == 0 }, time.Second, 5*time.Millisecond)
+	_, ok := <-all
+	require.False(t, ok)
+
+	require.NoError(t, b.Close())
+	require.ErrorIs(t, b.Publish(context.Background(), PriceTopic("BTC"), nil), ErrClosed)
+}
+
+func TestRedisReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := miniredis.RunT(t)
+	b := NewRedis(server.Addr())
+	defer b.Close()
+
+	messages, err := b.Subscribe(ctx, AllPrices)
+	require.NoError(t, err)
+	require.NoError(t, b.Publish(ctx, PriceTopic("BTC"), []byte("before")))
+	require.Equal(t, "before", string(receive(t, messages)))
+
+	// a restarted server forgets its subscriptions until they are made again
+	server.Restart()
+	require.Eventually(t, func() bool {
+		return server.PubSubNumPat() == 1 && b.Publish(ctx, PriceTopic("BTC"), []byte("after")) == nil
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, "after", string(receive(t, messages)))
+}
+
+func TestRedisUnreachable(t *testing.T) {
+	b := NewRedis("127.0.0.1:1")
+	_, err := b.Subscribe(context.Background(), AllPrices)
+	require.Error(t, err)
+	require.Error(t, b.Publish(context.Background(), PriceTopic("BTC"), nil))
+}
@@ -0,0 +1,340 @@
+package candle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	queueBufferSize = 100
+	// sweepInterval is how often candles are closed once their interval is over, when no later tick closed them
+	sweepInterval = time.Second
+	flushTimeout  = 5 * time.Second
+)
+
+type key struct {
+	asset    string
+	currency string
+	interval Interval
+}
+
+// Aggregator builds candles of every interval for every asset and currency quoted by the ticks it is given.
+// Candles in progress are kept in memory and stored once their interval is over, late ticks are merged into
+// the stored candles they belong to.
+type Aggregator struct {
+	repo      repository.Candles
+	intervals []Interval
+	now       func() time.Time
+
+	mutex       sync.RWMutex
+	open        map[key]*model.Candle
+	subscribers map[*Subscription]struct{}
+}
+
+func NewAggregator(repo repository.Candles, intervals []Interval) *Aggregator {
+	return &Aggregator{
+		repo:        repo,
+		intervals:   intervals,
+		now:         time.Now,
+		open:        make(map[key]*model.Candle),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Interval parses an interval candles are built for, the first one when value is empty.
+func (a *Aggregator) Interval(value string) (Interval, error) {
+	if value == "" {
+		return a.intervals[0], nil
+	}
+	interval, err := ParseInterval(value)
+	if err != nil {
+		return "", err
+	}
+	for _, built := range a.intervals {
+		if built == interval {
+			return interval, nil
+		}
+	}
+	return "", fmt.Errorf("candles are not built for interval %s", interval)
+}
+
+// Run aggregates the prices published on priceHub until ctx is done. It starts with the prices stored within
+// replay, so the candles of ticks stored while no instance was aggregating are corrected too.
+func (a *Aggregator) Run(ctx context.Context, priceHub *hub.Hub, replay time.Duration) {
+	// falling behind loses ticks rather than the subscription
+	sub := priceHub.Subscribe(ctx, hub.Filter{}, hub.PolicyDropOldest)
+	defer sub.Close()
+
+	if replay > 0 {
+		stored, err := priceHub.Replay(ctx, sub, a.now().Add(-replay))
+		if err != nil {
+			log.Err(err).Msg("failed to replay stored prices into candles")
+		}
+		for _, price := range stored {
+			a.Add(ctx, price)
+		}
+	}
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for open := true; open; {
+		select {
+		case price, ok := <-sub.C():
+			if !ok {
+				open = false
+				break
+			}
+			a.Add(ctx, price)
+		case <-ticker.C:
+			a.closeBefore(ctx, a.now())
+		}
+	}
+
+	// a restart merges the ticks it sees into the stored candle, so none are lost
+	flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	a.mutex.Lock()
+	inProgress := make([]*model.Candle, 0, len(a.open))
+	for _, candle := range a.open {
+		inProgress = append(inProgress, candle)
+	}
+	a.mutex.Unlock()
+	for _, candle := range inProgress {
+		a.store(flushCtx, candle)
+	}
+}
+
+// Add merges price into its candles of every interval in every currency it quotes, and publishes them.
+func (a *Aggregator) Add(ctx context.Context, price *model.CurrentPrice) {
+	at := price.Time.UpdatedISO
+	var closed, updated []*model.Candle
+
+	a.mutex.Lock()
+	for code, rate := range price.Bpi {
+		if rate.Value.IsZero() {
+			continue
+		}
+		for _, interval := range a.intervals {
+			k := key{asset: price.AssetSymbol(), currency: strings.ToUpper(code), interval: interval}
+			start := interval.Start(at)
+			current := a.open[k]
+			switch {
+			case current != nil && start.Before(current.Start):
+				// a late tick corrects the stored candle
+				late := newCandle(k, start)
+				late.Add(at, rate.Value)
+				late.Closed = true
+				closed = append(closed, late)
+				continue
+			case current != nil && start.After(current.Start):
+				current.Closed = true
+				closed = append(closed, current)
+				current = nil
+			}
+			if current == nil {
+				current = newCandle(k, start)
+				a.open[k] = current
+			}
+			current.Add(at, rate.Value)
+			update := *current
+			updated = append(updated, &update)
+		}
+	}
+	a.mutex.Unlock()
+
+	for i, candle := range closed {
+		closed[i] = a.store(ctx, candle)
+	}
+	a.publish(append(closed, updated...))
+}
+
+// closeBefore stores the candles whose interval is over at now.
+func (a *Aggregator) closeBefore(ctx context.Context, now time.Time) {
+	var closed []*model.Candle
+	a.mutex.Lock()
+	for k, candle := range a.open {
+		if candle.Start.Add(k.interval.Duration()).After(now) {
+			continue
+		}
+		delete(a.open, k)
+		candle.Closed = true
+		closed = append(closed, candle)
+	}
+	a.mutex.Unlock()
+
+	for i, candle := range closed {
+		closed[i] = a.store(ctx, candle)
+	}
+	a.publish(closed)
+}
+
+// store merges candle into the stored one and returns the result, candle itself when that failed.
+func (a *Aggregator) store(ctx context.Context, candle *model.Candle) *model.Candle {
+	merged, err := a.repo.Merge(ctx, candle)
+	if err != nil {
+		log.Err(err).Msgf("failed to store candle")
+		return candle
+	}
+	return merged
+}
+
+// Candles returns the candles matching query, the one in progress included.
+func (a *Aggregator) Candles(ctx context.Context, query repository.CandleQuery) ([]*model.Candle, error) {
+	stored, err := a.repo.GetRange(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	now := a.now()
+	for _, candle := range stored {
+		candle.Closed = !candle.Start.Add(Interval(candle.Interval).Duration()).After(now)
+	}
+
+	current, ok := a.Current(query.Asset, query.Currency, Interval(query.Interval))
+	if !ok || (!query.From.IsZero() && current.Start.Before(query.From)) || (!query.To.IsZero() && current.Start.After(query.To)) {
+		return stored, nil
+	}
+	// the candle in progress was stored too when an instance stopped during its interval
+	switch last := len(stored) - 1; {
+	case last >= 0 && stored[last].Start.Equal(current.Start):
+		stored[last].Merge(current)
+		stored[last].Closed = false
+	case last < 0 || stored[last].Start.Before(current.Start):
+		stored = append(stored, &current)
+		if query.Limit > 0 && len(stored) > query.Limit {
+			stored = stored[1:]
+		}
+	}
+	return stored, nil
+}
+
+// Current returns the candle in progress of asset quoted in currency.
+func (a *Aggregator) Current(asset, currency string, interval Interval) (model.Candle, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	candle, ok := a.open[key{asset: strings.ToUpper(asset), currency: strings.ToUpper(currency), interval: interval}]
+	if !ok {
+		return model.Candle{}, false
+	}
+	return *candle, true
+}
+
+// Subscribe registers a subscription receiving the updates of the candles of interval in the given assets and
+// currencies until ctx is done or it is closed, empty lists match everything.
+func (a *Aggregator) Subscribe(ctx context.Context, interval Interval, assets, currencies []string) *Subscription {
+	sub := &Subscription{
+		aggregator: a,
+		interval:   interval,
+		assets:     assets,
+		currencies: currencies,
+		queue:      make(chan *model.Candle, queueBufferSize),
+		done:       make(chan struct{}),
+	}
+	a.mutex.Lock()
+	a.subscribers[sub] = struct{}{}
+	a.mutex.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Close()
+		case <-sub.done:
+		}
+	}()
+	return sub
+}
+
+// publish queues candles for every matching subscriber, a slow subscriber misses updates instead of blocking.
+func (a *Aggregator) publish(candles []*model.Candle) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	for _, candle := range candles {
+		for sub := range a.subscribers {
+			if !sub.matches(candle) {
+				continue
+			}
+			select {
+			case sub.queue <- candle:
+			default:
+				log.Warn().Msgf("candle subscriber is too slow, dropping %s %s/%s update", candle.Interval, candle.Asset, candle.Currency)
+			}
+		}
+	}
+}
+
+func newCandle(k key, start time.Time) *model.Candle {
+	return &model.Candle{Asset: k.asset, Currency: k.currency, Interval: string(k.interval), Start: start}
+}
+
+// Subscription receives candle updates, see Aggregator.Subscribe.
+type Subscription struct {
+	aggregator *Aggregator
+	interval   Interval
+	assets     []string
+	currencies []string
+	queue      chan *model.Candle
+	done       chan struct{}
+	once       sync.Once
+}
+
+// C delivers the updates, it is closed with the subscription.
+func (s *Subscription) C() <-chan *model.Candle {
+	return s.queue
+}
+
+// Current returns the candles in progress the subscription matches, ordered by asset and currency.
+func (s *Subscription) Current() []*model.Candle {
+	s.aggregator.mutex.RLock()
+	defer s.aggregator.mutex.RUnlock()
+	var res []*model.Candle
+	for _, candle := range s.aggregator.open {
+		if s.matches(candle) {
+			current := *candle
+			res = append(res, &current)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Asset != res[j].Asset {
+			return res[i].Asset < res[j].Asset
+		}
+		return res[i].Currency < res[j].Currency
+	})
+	return res
+}
+
+// Close unsubscribes, it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.aggregator.mutex.Lock()
+		delete(s.aggregator.subscribers, s)
+		close(s.queue)
+		s.aggregator.mutex.Unlock()
+		close(s.done)
+	})
+}
+
+func (s *Subscription) matches(candle *model.Candle) bool {
+	return candle.Interval == string(s.interval) && containsFold(s.assets, candle.Asset) &&
+		containsFold(s.currencies, candle.Currency)
+}
+
+// containsFold reports whether values contain value ignoring case, empty values contain everything.
+func containsFold(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package candle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+
+func tick(asset string, second int, usd int64) *model.CurrentPrice {
+	return &model.CurrentPrice{
+		Asset: asset,
+		Time:  model.CurrentPriceTime{UpdatedISO: base.Add(time.Duration(second) * time.Second)},
+		Bpi:   map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(usd, 0)}},
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) *model.Candle {
+	select {
+	case candle := <-sub.C():
+		return candle
+	case <-time.After(time.Second):
+		t.Fatal("candle wasn't delivered")
+	}
+	return nil
+}
+
+func requireOHLC(t *testing.T, candle *model.Candle, open, high, low, close int64) {
+	require.Equal(t, []string{
+		model.NewDecimal(open, 0).String(), model.NewDecimal(high, 0).String(),
+		model.NewDecimal(low, 0).String(), model.NewDecimal(close, 0).String(),
+	}, []string{candle.Open.String(), candle.High.String(), candle.Low.String(), candle.Close.String()})
+}
+
+// storeAsIs merges candles into nothing, so the stored candle is the given one.
+func storeAsIs(repo *mockRepo.MockCandles) *mock.Call {
+	return repo.On("Merge", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, candle *model.Candle) (*model.Candle, error) {
+			stored := *candle
+			return &stored, nil
+		})
+}
+
+func TestAggregatorBuildsCandles(t *testing.T) {
+	repo := mockRepo.NewMockCandles(t)
+	storeAsIs(repo).Once()
+	a := NewAggregator(repo, []Interval{Minute, FiveMinutes})
+	sub := a.Subscribe(context.Background(), Minute, []string{"btc"}, nil)
+	defer sub.Close()
+
+	for i, usd := range []int64{100, 120, 90, 110} {
+		a.Add(context.Background(), tick("BTC", i*10, usd))
+		require.Equal(t, base, receive(t, sub).Start)
+	}
+	a.Add(context.Background(), tick("ETH", 5, 2000))
+	current, ok := a.Current("btc", "usd", Minute)
+	require.True(t, ok)
+	requireOHLC(t, &current, 100, 120, 90, 110)
+	require.False(t, current.Closed)
+
+	// the next minute closes the candle, which is stored before the new one is published
+	a.Add(context.Background(), tick("BTC", 65, 130))
+	closed := receive(t, sub)
+	require.True(t, closed.Closed)
+	require.Equal(t, base, closed.Start)
+	requireOHLC(t, closed, 100, 120, 90, 110)
+	repo.AssertCalled(t, "Merge", mock.Anything, mock.MatchedBy(func(c *model.Candle) bool {
+		return c.Asset == "BTC" && c.Interval == "1m" && c.Start.Equal(base)
+	}))
+	opened := receive(t, sub)
+	require.Equal(t, base.Add(time.Minute), opened.Start)
+	requireOHLC(t, opened, 130, 130, 130, 130)
+
+	// five minute candles keep going
+	current, ok = a.Current("BTC", "USD", FiveMinutes)
+	require.True(t, ok)
+	requireOHLC(t, &current, 100, 130, 90, 130)
+	require.Len(t, sub.Current(), 1)
+}
+
+func TestAggregatorCorrectsClosedCandles(t *testing.T) {
+	repo := mockRepo.NewMockCandles(t)
+	a := NewAggregator(repo, []Interval{Minute})
+	sub := a.Subscribe(context.Background(), Minute, nil, nil)
+	defer sub.Close()
+
+	a.Add(context.Background(), tick("BTC", 65, 130))
+	receive(t, sub)
+
+	// a tick of the previous minute arrives late, the stored candle is merged with it
+	stored := &model.Candle{Asset: "BTC", Currency: "USD", Interval: "1m", Start: base, FirstTick: base, LastTick: base.Add(50 * time.Second),
+		Open: model.NewDecimal(100, 0), High: model.NewDecimal(120, 0), Low: model.NewDecimal(90, 0), Close: model.NewDecimal(110, 0)}
+	repo.On("Merge", mock.Anything, mock.MatchedBy(func(c *model.Candle) bool { return c.Start.Equal(base) && c.Closed })).
+		Return(func(_ context.Context, late *model.Candle) (*model.Candle, error) {
+			merged := *stored
+			merged.Merge(*late)
+			merged.Closed = late.Closed
+			return &merged, nil
+		}).Once()
+	a.Add(context.Background(), tick("BTC", 55, 80))
+
+	corrected := receive(t, sub)
+	require.True(t, corrected.Closed)
+	requireOHLC(t, corrected, 100, 120, 80, 80)
+	current, _ := a.Current("BTC", "USD", Minute)
+	requireOHLC(t, &current, 130, 130, 130, 130)
+
+	// candles whose interval is over are closed without waiting for the next tick
+	storeAsIs(repo).Once()
+	a.closeBefore(context.Background(), base.Add(2*time.Minute))
+	require.True(t, receive(t, sub).Closed)
+	_, ok := a.Current("BTC", "USD", Minute)
+	require.False(t, ok)
+}
+
+func TestAggregatorCandles(t *testing.T) {
+	repo := mockRepo.NewMockCandles(t)
+	a := NewAggregator(repo, []Interval{Minute})
+	a.now = func() time.Time { return base.Add(90 * time.Second) }
+	a.Add(context.Background(), tick("BTC", 70, 130))
+
+	query := repository.CandleQuery{Asset: "BTC", Currency: "USD", Interval: "1m", Limit: 2}
+	repo.On("GetRange", mock.Anything, query).Return([]*model.Candle{
+		{Asset: "BTC", Currency: "USD", Interval: "1m", Start: base.Add(-time.Minute)},
+		{Asset: "BTC", Currency: "USD", Interval: "1m", Start: base},
+	}, nil).Once()
+	candles, err := a.Candles(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	require.True(t, candles[0].Closed)
+	require.Equal(t, base.Add(time.Minute), candles[1].Start)
+	require.False(t, candles[1].Closed)
+
+	// a candle stored by a stopped instance is completed by the one in progress
+	partial := &model.Candle{Asset: "BTC", Currency: "USD", Interval: "1m", Start: base.Add(time.Minute),
+		FirstTick: base.Add(61 * time.Second), LastTick: base.Add(61 * time.Second),
+		Open: model.NewDecimal(125, 0), High: model.NewDecimal(125, 0), Low: model.NewDecimal(125, 0), Close: model.NewDecimal(125, 0)}
+	repo.On("GetRange", mock.Anything, query).Return([]*model.Candle{partial}, nil).Once()
+	candles, err = a.Candles(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	requireOHLC(t, candles[0], 125, 130, 125, 130)
+}
+
+func TestParseInterval(t *testing.T) {
+	intervals, err := ParseIntervals([]string{"1m", " 5M", "", "1h", "1d"})
+	require.NoError(t, err)
+	require.Equal(t, []Interval{Minute, FiveMinutes, Hour, Day}, intervals)
+	_, err = ParseInterval("2m")
+	require.Error(t, err)
+	require.Equal(t, time.Date(2024, 1, 23, 0, 0, 0, 0, time.UTC), Day.Start(base.Add(5*time.Hour)))
+}
@@ -0,0 +1,59 @@
+// Package candle aggregates the tick stream into OHLC candles.
+package candle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Interval is the span of time a candle covers.
+type Interval string
+
+const (
+	Minute      Interval = "1m"
+	FiveMinutes Interval = "5m"
+	Hour        Interval = "1h"
+	Day         Interval = "1d"
+)
+
+var durations = map[Interval]time.Duration{
+	Minute:      time.Minute,
+	FiveMinutes: 5 * time.Minute,
+	Hour:        time.Hour,
+	Day:         24 * time.Hour,
+}
+
+// ParseInterval accepts "1m", "5m", "1h" and "1d".
+func ParseInterval(value string) (Interval, error) {
+	interval := Interval(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := durations[interval]; !ok {
+		return "", fmt.Errorf("unknown candle interval %q, expected 1m, 5m, 1h or 1d", value)
+	}
+	return interval, nil
+}
+
+// ParseIntervals parses every value, ignoring empty ones.
+func ParseIntervals(values []string) ([]Interval, error) {
+	var res []Interval
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		interval, err := ParseInterval(value)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, interval)
+	}
+	return res, nil
+}
+
+func (i Interval) Duration() time.Duration {
+	return durations[i]
+}
+
+// Start returns the start of the candle t belongs to, candles are aligned to UTC midnight.
+func (i Interval) Start(t time.Time) time.Time {
+	return t.UTC().Truncate(i.Duration())
+}
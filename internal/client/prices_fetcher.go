@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"code.injective.org/service/pricefetcher/internal/model"
@@ -13,6 +14,12 @@ type PriceProvider interface {
 	GetPrice() (*model.CurrentPrice, error)
 }
 
+// StreamProvider pushes prices as soon as the upstream publishes them instead of being polled.
+// Stream blocks until ctx is cancelled, connection failures are reported on errors.
+type StreamProvider interface {
+	Stream(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error
+}
+
 type PriceFetcher interface {
 	RunPriceFetcher(ctx context.Context, fetcher PriceProvider, receiver chan *model.CurrentPrice, errors chan error, saveData bool)
 }
@@ -20,8 +27,24 @@ type PriceFetcher interface {
 type priceFetcher struct {
 	pricesRepo     repository.Prices
 	fetcher        PriceProvider
+	stream         StreamProvider
 	tickerInterval int
 	saveData       bool
+
+	validator  *Validator
+	quarantine repository.Quarantine
+	publisher  *Publisher
+	sequencer  *Sequencer
+}
+
+// Sequencer is shared by the fetchers of every asset, so the sequence numbers prices are stored under reach the
+// broadcast in increasing order. Clients resume from the highest one they saw without missing any.
+type Sequencer struct {
+	mutex sync.Mutex
+}
+
+func NewSequencer() *Sequencer {
+	return &Sequencer{}
 }
 
 func NewPriceFetcher(pricesRepo repository.Prices, fetcher PriceProvider, tickerInterval int, saveData bool) *priceFetcher {
@@ -33,6 +56,34 @@ func NewPriceFetcher(pricesRepo repository.Prices, fetcher PriceProvider, ticker
 	}
 }
 
+// NewStreamFetcher feeds prices pushed by stream into the same pipeline RunPriceFetcher does.
+func NewStreamFetcher(pricesRepo repository.Prices, stream StreamProvider, saveData bool) *priceFetcher {
+	return &priceFetcher{
+		pricesRepo: pricesRepo,
+		stream:     stream,
+		saveData:   saveData,
+	}
+}
+
+// WithValidator checks every price before it is saved or broadcast, rejected ones are stored in quarantine.
+func (p *priceFetcher) WithValidator(validator *Validator, quarantine repository.Quarantine) *priceFetcher {
+	p.validator = validator
+	p.quarantine = quarantine
+	return p
+}
+
+// WithSequencer stores and broadcasts prices one at a time with the other fetchers sharing sequencer.
+func (p *priceFetcher) WithSequencer(sequencer *Sequencer) *priceFetcher {
+	p.sequencer = sequencer
+	return p
+}
+
+// WithPublisher only saves and broadcasts prices the publisher lets through, tagged with their round.
+func (p *priceFetcher) WithPublisher(publisher *Publisher) *priceFetcher {
+	p.publisher = publisher
+	return p
+}
+
 func (p *priceFetcher) RunPriceFetcher(ctx context.Context, receiver chan *model.CurrentPrice, errors chan error) {
 	ticker := time.NewTicker(5 * time.Second)
 	for {
@@ -48,19 +99,72 @@ func (p *priceFetcher) RunPriceFetcher(ctx context.Context, receiver chan *model
 				continue
 			}
 
-			// we can run it in separate go routine
-			log.Info().Msgf("received price %v", price)
-			if p.saveData {
-				err = p.pricesRepo.Create(ctx, price)
-				if err != nil {
-					log.Err(err).Msg("error saving to DB")
+			p.publish(ctx, price, receiver)
+		}
+	}
+}
+
+func (p *priceFetcher) RunStreamFetcher(ctx context.Context, receiver chan *model.CurrentPrice, errors chan error) {
+	prices := make(chan *model.CurrentPrice)
+	go func() {
+		if err := p.stream.Stream(ctx, prices, errors); err != nil {
+			log.Err(err).Msg("price stream stopped")
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case price := <-prices:
+			p.publish(ctx, price, receiver)
+		}
+	}
+}
+
+func (p *priceFetcher) publish(ctx context.Context, price *model.CurrentPrice, receiver chan *model.CurrentPrice) {
+	// we can run it in separate go routine
+	log.Info().Msgf("received price %v", price)
+	if p.validator != nil {
+		if reason := p.validator.Validate(price); reason != nil {
+			log.Warn().Msgf("price of %s rejected: %v", price.AssetSymbol(), reason)
+			if p.quarantine != nil {
+				if err := p.quarantine.Create(ctx, price, reason.Error()); err != nil {
+					log.Err(err).Msg("error saving rejected price to DB")
 				}
 			}
+			return
+		}
+	}
 
-			// if nobody read it, it will stack, so using unblocking writes
-			go func() {
-				receiver <- price
-			}()
+	if p.publisher != nil {
+		round, ok, err := p.publisher.Next(ctx, price)
+		if err != nil {
+			log.Err(err).Msg("error assigning price round")
+			return
 		}
+		if !ok {
+			log.Debug().Msgf("price of %s within deviation threshold, not published", price.AssetSymbol())
+			return
+		}
+		price.Round = round
+	}
+
+	// the price is broadcast before the next one gets a sequence number
+	if p.sequencer != nil {
+		p.sequencer.mutex.Lock()
+		defer p.sequencer.mutex.Unlock()
+	}
+	if p.saveData {
+		err := p.pricesRepo.Create(ctx, price)
+		if err != nil {
+			log.Err(err).Msg("error saving to DB")
+		}
+	}
+
+	select {
+	case receiver <- price:
+	case <-ctx.Done():
 	}
 }
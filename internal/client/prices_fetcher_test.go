@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type streamFunc func(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error
+
+func (f streamFunc) Stream(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+	return f(ctx, prices, errors)
+}
+
+func TestStreamFetcherSavesAndForwards(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tick := &model.CurrentPrice{Time: model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0)}}
+	stream := streamFunc(func(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+		prices <- tick
+		<-ctx.Done()
+		return nil
+	})
+
+	mockPrices := mockRepo.NewMockPrices(t)
+	mockPrices.On("Create", mock.Anything, tick).Return(nil).Once()
+
+	receiver := make(chan *model.CurrentPrice)
+	errors := make(chan error)
+	go NewStreamFetcher(mockPrices, stream, true).RunStreamFetcher(ctx, receiver, errors)
+
+	select {
+	case price := <-receiver:
+		require.Equal(t, tick, price)
+	case <-time.After(5 * time.Second):
+		t.Fatal("price wasn't forwarded")
+	}
+}
+
+func TestFetcherQuarantinesRejectedPrices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	good := tickAt(now, 40000)
+	bad := tickAt(now, 0)
+	stream := streamFunc(func(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+		prices <- bad
+		prices <- good
+		<-ctx.Done()
+		return nil
+	})
+
+	mockPrices := mockRepo.NewMockPrices(t)
+	mockPrices.On("Create", mock.Anything, good).Return(nil).Once()
+	mockQuarantine := mockRepo.NewMockQuarantine(t)
+	mockQuarantine.On("Create", mock.Anything, bad, mock.AnythingOfType("string")).Return(nil).Once()
+
+	receiver := make(chan *model.CurrentPrice)
+	errors := make(chan error)
+	fetcher := NewStreamFetcher(mockPrices, stream, true).
+		WithValidator(NewValidator(ValidationRules{MaxDeviation: 0.1, Window: 5}), mockQuarantine)
+	go fetcher.RunStreamFetcher(ctx, receiver, errors)
+
+	select {
+	case price := <-receiver:
+		require.Equal(t, good, price)
+	case <-time.After(5 * time.Second):
+		t.Fatal("price wasn't forwarded")
+	}
+}
+
+func TestFetchersBroadcastInSequenceOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// sequence numbers are assigned before the insert, which takes longer for some prices than for others
+	var seq atomic.Uint64
+	mockPrices := mockRepo.NewMockPrices(t)
+	mockPrices.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		next := seq.Add(1)
+		time.Sleep(time.Duration(next%3) * time.Millisecond)
+		args.Get(1).(*model.CurrentPrice).Seq = next
+	}).Return(nil)
+
+	const perAsset = 50
+	receiver := make(chan *model.CurrentPrice)
+	errors := make(chan error)
+	sequencer := NewSequencer()
+	for _, asset := range []string{"BTC", "ETH"} {
+		asset := asset
+		stream := streamFunc(func(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+			for i := 0; i < perAsset; i++ {
+				prices <- &model.CurrentPrice{Asset: asset}
+			}
+			<-ctx.Done()
+			return nil
+		})
+		go NewStreamFetcher(mockPrices, stream, true).WithSequencer(sequencer).RunStreamFetcher(ctx, receiver, errors)
+	}
+
+	var last uint64
+	for i := 0; i < 2*perAsset; i++ {
+		select {
+		case price := <-receiver:
+			require.Greater(t, price.Seq, last)
+			last = price.Seq
+		case <-time.After(5 * time.Second):
+			t.Fatal("price wasn't forwarded")
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"code.injective.org/service/pricefetcher/internal/client"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// Consensus is the way aggregated quotes are reduced into a single price.
+type Consensus string
+
+const (
+	ConsensusMedian   Consensus = "median"
+	ConsensusWeighted Consensus = "weighted"
+)
+
+// Source is a named upstream taking part in the aggregation.
+type Source struct {
+	Name     string
+	Provider client.PriceProvider
+	Weight   float64
+}
+
+type aggregatorProvider struct {
+	sources      []Source
+	consensus    Consensus
+	maxDeviation float64
+	minSources   int
+}
+
+type sourceQuote struct {
+	source Source
+	price  *model.CurrentPrice
+}
+
+// NewAggregatorProvider polls every source concurrently on each GetPrice call.
+// Quotes deviating from the cross-source median by more than maxDeviation (a fraction, 0.05 = 5%)
+// are discarded, and at least minSources quotes must survive for a price to be emitted.
+func NewAggregatorProvider(sources []Source, consensus Consensus, maxDeviation float64, minSources int) *aggregatorProvider {
+	if minSources < 1 {
+		minSources = 1
+	}
+	return &aggregatorProvider{
+		sources:      sources,
+		consensus:    consensus,
+		maxDeviation: maxDeviation,
+		minSources:   minSources,
+	}
+}
+
+func (p *aggregatorProvider) GetPrice() (*model.CurrentPrice, error) {
+	quotes := p.collect()
+	if len(quotes) == 0 {
+		return nil, errors.New("no provider returned a price")
+	}
+
+	quotes = p.dropOutliers(quotes)
+	if len(quotes) < p.minSources {
+		return nil, fmt.Errorf("only %d sources agreed on price, %d required", len(quotes), p.minSources)
+	}
+
+	first := quotes[0].price
+	res := &model.CurrentPrice{
+		Asset:      first.Asset,
+		Time:       first.Time,
+		Disclaimer: first.Disclaimer,
+		ChartName:  first.ChartName,
+		Bpi:        make(map[string]model.CurrentPriceRate),
+	}
+	for _, q := range quotes {
+		if q.price.Time.UpdatedISO.After(res.Time.UpdatedISO) {
+			res.Time = q.price.Time
+		}
+		res.Sources = append(res.Sources, q.source.Name)
+	}
+
+	for _, code := range currencies(quotes) {
+		var rate model.CurrentPriceRate
+		var values []model.Decimal
+		var weights []float64
+		for _, q := range quotes {
+			if r, ok := q.price.Rate(code); ok && r.Value.Sign() > 0 {
+				if len(values) == 0 {
+					rate = r
+				}
+				values = append(values, r.Value)
+				weights = append(weights, q.source.Weight)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		rate.Value = model.RoundRate(code, p.reduce(values, weights, model.DecimalSpecFor(code).Scale))
+		rate.Rate = rate.Value.String()
+		res.SetRate(code, rate)
+	}
+
+	return res, nil
+}
+
+// collect queries all sources in parallel and keeps successful answers in source order.
+func (p *aggregatorProvider) collect() []sourceQuote {
+	results := make([]*model.CurrentPrice, len(p.sources))
+	var wg sync.WaitGroup
+	for i := range p.sources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			price, err := p.sources[i].Provider.GetPrice()
+			if err != nil {
+				log.Err(err).Msgf("source %s failed to return price", p.sources[i].Name)
+				return
+			}
+			results[i] = price
+		}(i)
+	}
+	wg.Wait()
+
+	var quotes []sourceQuote
+	for i, price := range results {
+		if price != nil {
+			quotes = append(quotes, sourceQuote{source: p.sources[i], price: price})
+		}
+	}
+	return quotes
+}
+
+// dropOutliers removes every quote having at least one rate too far from the median of that rate.
+func (p *aggregatorProvider) dropOutliers(quotes []sourceQuote) []sourceQuote {
+	if p.maxDeviation <= 0 || len(quotes) < 3 {
+		return quotes
+	}
+
+	outlier := make([]bool, len(quotes))
+	for _, code := range currencies(quotes) {
+		var values []float64
+		for _, q := range quotes {
+			if r, ok := q.price.Rate(code); ok && r.Value.Sign() > 0 {
+				values = append(values, r.Value.Float64())
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		m := median(values)
+		for j, q := range quotes {
+			r, ok := q.price.Rate(code)
+			if ok && r.Value.Sign() > 0 && math.Abs(r.Value.Float64()-m)/m > p.maxDeviation {
+				outlier[j] = true
+			}
+		}
+	}
+
+	var res []sourceQuote
+	for i, q := range quotes {
+		if outlier[i] {
+			log.Warn().Msgf("discarding outlier price from %s", q.source.Name)
+			continue
+		}
+		res = append(res, q)
+	}
+	return res
+}
+
+// reduce merges quotes of one currency, divisions keep scale digits.
+func (p *aggregatorProvider) reduce(values []model.Decimal, weights []float64, scale int32) model.Decimal {
+	if p.consensus != ConsensusWeighted {
+		return decimalMedian(values, scale)
+	}
+
+	var sum, total model.Decimal
+	for i, v := range values {
+		weight, err := model.DecimalFromFloat(weights[i])
+		if err != nil {
+			return decimalMedian(values, scale)
+		}
+		sum = sum.Add(v.Mul(weight))
+		total = total.Add(weight)
+	}
+	if total.Sign() <= 0 {
+		return decimalMedian(values, scale)
+	}
+	return sum.Quo(total, scale)
+}
+
+func decimalMedian(values []model.Decimal, scale int32) model.Decimal {
+	sorted := append([]model.Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).Quo(model.NewDecimal(2, 0), scale)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// currencies returns the union of currencies quoted by any source, sorted.
+func currencies(quotes []sourceQuote) []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, q := range quotes {
+		for _, code := range q.price.Currencies() {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type providerFunc func() (*model.CurrentPrice, error)
+
+func (f providerFunc) GetPrice() (*model.CurrentPrice, error) {
+	return f()
+}
+
+func fixedPrice(usd float64, updated time.Time) providerFunc {
+	return func() (*model.CurrentPrice, error) {
+		value, err := model.DecimalFromFloat(usd)
+		if err != nil {
+			return nil, err
+		}
+		return &model.CurrentPrice{
+			Time: model.CurrentPriceTime{UpdatedISO: updated},
+			Bpi: map[string]model.CurrentPriceRate{
+				"USD": {Code: "USD", Value: value},
+				"EUR": {Code: "EUR", Value: value.Quo(model.NewDecimal(2, 0), 8)},
+			},
+		}, nil
+	}
+}
+
+func failing() providerFunc {
+	return func() (*model.CurrentPrice, error) {
+		return nil, errors.New("upstream down")
+	}
+}
+
+func TestAggregatorMedianDropsFailuresAndOutliers(t *testing.T) {
+	now := time.Unix(1706015736, 0)
+	agg := NewAggregatorProvider([]Source{
+		{Name: "a", Provider: fixedPrice(100, now), Weight: 1},
+		{Name: "b", Provider: fixedPrice(102, now.Add(time.Second)), Weight: 1},
+		{Name: "c", Provider: fixedPrice(150, now), Weight: 1},
+		{Name: "d", Provider: fixedPrice(101, now), Weight: 1},
+		{Name: "e", Provider: failing(), Weight: 1},
+	}, ConsensusMedian, 0.05, 2)
+
+	price, err := agg.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "d"}, price.Sources)
+	require.Equal(t, "101", price.Bpi["USD"].Value.String())
+	require.Equal(t, "50.5", price.Bpi["EUR"].Value.String())
+	require.Equal(t, now.Add(time.Second), price.Time.UpdatedISO)
+}
+
+func TestAggregatorWeighted(t *testing.T) {
+	now := time.Unix(1706015736, 0)
+	agg := NewAggregatorProvider([]Source{
+		{Name: "a", Provider: fixedPrice(100, now), Weight: 3},
+		{Name: "b", Provider: fixedPrice(104, now), Weight: 1},
+	}, ConsensusWeighted, 0.05, 1)
+
+	price, err := agg.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, "101", price.Bpi["USD"].Value.String())
+}
+
+func TestAggregatorQuorum(t *testing.T) {
+	now := time.Unix(1706015736, 0)
+	agg := NewAggregatorProvider([]Source{
+		{Name: "a", Provider: fixedPrice(100, now), Weight: 1},
+		{Name: "b", Provider: failing(), Weight: 1},
+	}, ConsensusMedian, 0.05, 2)
+
+	_, err := agg.GetPrice()
+	require.Error(t, err)
+
+	agg = NewAggregatorProvider([]Source{{Name: "b", Provider: failing(), Weight: 1}}, ConsensusMedian, 0.05, 1)
+	_, err = agg.GetPrice()
+	require.Error(t, err)
+}
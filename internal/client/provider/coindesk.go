@@ -9,20 +9,23 @@ import (
 	"time"
 )
 
+const coinDeskURL = "https://api.coindesk.com/v1/bpi/currentprice.json"
+
+// coinDeskProvider only quotes BTC, coin is attached to every price as its asset.
 type coinDeskProvider struct {
 	coin string
+	url  string
 }
 
 func NewCoinDeskProvider(coin string) *coinDeskProvider {
-	return &coinDeskProvider{coin: coin}
+	return &coinDeskProvider{coin: coin, url: coinDeskURL}
 }
 
 func (p *coinDeskProvider) GetPrice() (*model.CurrentPrice, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	url := "https://api.coindesk.com/v1/bpi/currentprice.json"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", p.url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +35,7 @@ func (p *coinDeskProvider) GetPrice() (*model.CurrentPrice, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
 		return nil, fmt.Errorf("provider returned wrong status code %d", res.StatusCode)
@@ -42,5 +46,10 @@ func (p *coinDeskProvider) GetPrice() (*model.CurrentPrice, error) {
 	if err != nil {
 		return nil, err
 	}
+	currentPrice.Asset = p.coin
+	for code, rate := range currentPrice.Bpi {
+		rate.Value = model.RoundRate(code, rate.Value)
+		currentPrice.Bpi[code] = rate
+	}
 	return &currentPrice, nil
 }
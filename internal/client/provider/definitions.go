@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/client"
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+// Provider types accepted in the providers file.
+const (
+	TypeCoinDesk  = "coindesk"
+	TypeHTTPJSON  = "http"
+	TypeWebsocket = "websocket"
+)
+
+// Definition is a single entry of the providers file. HTTPJSONConfig fields are used by "http" and "websocket" providers.
+type Definition struct {
+	Type   string  `json:"type"`
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	HTTPJSONConfig
+	Subscribe      string `json:"subscribe"`
+	ReconnectDelay string `json:"reconnect_delay"`
+}
+
+// StreamSource is a named push-style upstream.
+type StreamSource struct {
+	Name     string
+	Provider client.StreamProvider
+}
+
+// LoadDefinitions reads a JSON array of provider definitions.
+func LoadDefinitions(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []Definition
+	if err = json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("wrong providers file %s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// GroupByAsset splits definitions by the asset they quote, assets are returned in order of first appearance.
+func GroupByAsset(defs []Definition) ([]string, map[string][]Definition) {
+	var assets []string
+	groups := make(map[string][]Definition)
+	for _, def := range defs {
+		asset := strings.ToUpper(def.Asset)
+		if asset == "" {
+			asset = model.DefaultAsset
+		}
+		if _, ok := groups[asset]; !ok {
+			assets = append(assets, asset)
+		}
+		groups[asset] = append(groups[asset], def)
+	}
+	return assets, groups
+}
+
+// NewSources builds polling providers from their definitions, keeping the order of the file.
+// Streaming definitions are skipped, see NewStreams.
+func NewSources(defs []Definition) ([]Source, error) {
+	sources := make([]Source, 0, len(defs))
+	for i, def := range defs {
+		if def.Type == TypeWebsocket {
+			continue
+		}
+		if def.Name == "" {
+			def.Name = fmt.Sprintf("%s-%d", def.Type, i)
+		}
+		if def.Weight == 0 {
+			def.Weight = 1
+		}
+
+		source := Source{Name: def.Name, Weight: def.Weight}
+		switch def.Type {
+		case TypeCoinDesk:
+			if def.Asset != "" && !strings.EqualFold(def.Asset, model.DefaultAsset) {
+				return nil, fmt.Errorf("provider %s: coindesk only quotes %s", def.Name, model.DefaultAsset)
+			}
+			source.Provider = NewCoinDeskProvider(model.DefaultAsset)
+		case TypeHTTPJSON:
+			p, err := NewHTTPJSONProvider(def.HTTPJSONConfig)
+			if err != nil {
+				return nil, fmt.Errorf("provider %s: %w", def.Name, err)
+			}
+			source.Provider = p
+		default:
+			return nil, fmt.Errorf("provider %s has unknown type %q", def.Name, def.Type)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// NewStreams builds streaming providers out of "websocket" definitions.
+func NewStreams(defs []Definition) ([]StreamSource, error) {
+	var streams []StreamSource
+	for i, def := range defs {
+		if def.Type != TypeWebsocket {
+			continue
+		}
+		if def.Name == "" {
+			def.Name = fmt.Sprintf("%s-%d", def.Type, i)
+		}
+
+		cfg := WebsocketConfig{HTTPJSONConfig: def.HTTPJSONConfig, Subscribe: def.Subscribe}
+		if def.ReconnectDelay != "" {
+			delay, err := time.ParseDuration(def.ReconnectDelay)
+			if err != nil {
+				return nil, fmt.Errorf("provider %s: wrong reconnect delay: %w", def.Name, err)
+			}
+			cfg.ReconnectDelay = delay
+		}
+		p, err := NewWebsocketProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", def.Name, err)
+		}
+		streams = append(streams, StreamSource{Name: def.Name, Provider: p})
+	}
+	return streams, nil
+}
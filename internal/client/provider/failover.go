@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// FailoverOptions controls when a provider is considered unhealthy and how it gets back in rotation.
+type FailoverOptions struct {
+	// Window is the number of recent calls kept per provider to compute its health.
+	Window int
+	// MinSuccessRate below which a provider is demoted.
+	MinSuccessRate float64
+	// MaxLatency is the highest average latency tolerated before demotion, zero disables the check.
+	MaxLatency time.Duration
+	// RecoverAfter is the number of consecutive successful probes needed to promote a provider back.
+	RecoverAfter int
+	// ProbeInterval is how often a demoted provider is given a trial call.
+	ProbeInterval time.Duration
+}
+
+// ProviderHealth is a snapshot of the health score of a single provider.
+type ProviderHealth struct {
+	Name        string
+	SuccessRate float64
+	AvgLatency  time.Duration
+	Demoted     bool
+}
+
+type callResult struct {
+	ok      bool
+	latency time.Duration
+}
+
+type providerState struct {
+	results   []callResult
+	demoted   bool
+	streak    int
+	nextProbe time.Time
+}
+
+type failoverProvider struct {
+	sources []Source
+	opts    FailoverOptions
+	now     func() time.Time
+
+	mutex  sync.Mutex
+	states []*providerState
+}
+
+// NewFailoverProvider wraps providers ordered by preference. GetPrice asks healthy providers first,
+// demoted ones only get a call when their probe is due or when every healthy provider failed.
+func NewFailoverProvider(sources []Source, opts FailoverOptions) *failoverProvider {
+	if opts.Window < 1 {
+		opts.Window = 10
+	}
+	if opts.RecoverAfter < 1 {
+		opts.RecoverAfter = 1
+	}
+
+	states := make([]*providerState, len(sources))
+	for i := range states {
+		states[i] = &providerState{}
+	}
+	return &failoverProvider{
+		sources: sources,
+		opts:    opts,
+		now:     time.Now,
+		states:  states,
+	}
+}
+
+func (p *failoverProvider) GetPrice() (*model.CurrentPrice, error) {
+	var errs []error
+	for _, i := range p.order() {
+		start := time.Now()
+		price, err := p.sources[i].Provider.GetPrice()
+		p.record(i, err == nil, time.Since(start))
+		if err != nil {
+			log.Err(err).Msgf("provider %s failed, trying next one", p.sources[i].Name)
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(price.Sources) == 0 {
+			price.Sources = []string{p.sources[i].Name}
+		}
+		return price, nil
+	}
+
+	return nil, errors.Join(append([]error{errors.New("all providers failed")}, errs...)...)
+}
+
+// Health returns the current score of every wrapped provider in configured order.
+func (p *failoverProvider) Health() []ProviderHealth {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	res := make([]ProviderHealth, len(p.sources))
+	for i, state := range p.states {
+		rate, latency := state.score()
+		res[i] = ProviderHealth{
+			Name:        p.sources[i].Name,
+			SuccessRate: rate,
+			AvgLatency:  latency,
+			Demoted:     state.demoted,
+		}
+	}
+	return res
+}
+
+// order puts healthy providers and demoted ones due for a probe in configured order,
+// the rest of demoted providers are kept as a last resort.
+func (p *failoverProvider) order() []int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	var active, fallback []int
+	for i, state := range p.states {
+		if !state.demoted || !now.Before(state.nextProbe) {
+			active = append(active, i)
+			continue
+		}
+		fallback = append(fallback, i)
+	}
+	return append(active, fallback...)
+}
+
+func (p *failoverProvider) record(i int, ok bool, latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	state := p.states[i]
+	state.results = append(state.results, callResult{ok: ok, latency: latency})
+	if len(state.results) > p.opts.Window {
+		state.results = state.results[len(state.results)-p.opts.Window:]
+	}
+
+	name := p.sources[i].Name
+	if state.demoted {
+		state.nextProbe = p.now().Add(p.opts.ProbeInterval)
+		if !ok || (p.opts.MaxLatency > 0 && latency > p.opts.MaxLatency) {
+			state.streak = 0
+			return
+		}
+		state.streak++
+		if state.streak >= p.opts.RecoverAfter {
+			log.Info().Msgf("provider %s recovered, promoting", name)
+			state.demoted = false
+			state.streak = 0
+			// the window may be shorter than the streak
+			state.results = state.results[len(state.results)-min(p.opts.RecoverAfter, len(state.results)):]
+		}
+		return
+	}
+
+	rate, avg := state.score()
+	if rate < p.opts.MinSuccessRate || (p.opts.MaxLatency > 0 && avg > p.opts.MaxLatency) {
+		log.Warn().Msgf("provider %s is unhealthy (success rate %.2f, latency %s), demoting", name, rate, avg)
+		state.demoted = true
+		state.streak = 0
+		state.nextProbe = p.now().Add(p.opts.ProbeInterval)
+	}
+}
+
+func (s *providerState) score() (float64, time.Duration) {
+	if len(s.results) == 0 {
+		return 1, 0
+	}
+
+	var ok int
+	var latency time.Duration
+	for _, r := range s.results {
+		if r.ok {
+			ok++
+		}
+		latency += r.latency
+	}
+	return float64(ok) / float64(len(s.results)), latency / time.Duration(len(s.results))
+}
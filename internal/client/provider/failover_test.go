@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func coinDeskStandIn(t *testing.T, usd float64, healthy *atomic.Bool) *coinDeskProvider {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintf(w, `{"time":{"updatedISO":"2024-01-23T13:15:36+00:00"},"bpi":{"USD":{"code":"USD","rate_float":%f}}}`, usd)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &coinDeskProvider{coin: "BTC", url: srv.URL}
+}
+
+func TestFailoverDemotesAndPromotes(t *testing.T) {
+	var primaryUp, backupUp atomic.Bool
+	primaryUp.Store(true)
+	backupUp.Store(true)
+
+	now := time.Unix(1706015736, 0)
+	failover := NewFailoverProvider([]Source{
+		{Name: "primary", Provider: coinDeskStandIn(t, 100, &primaryUp)},
+		{Name: "backup", Provider: coinDeskStandIn(t, 200, &backupUp)},
+	}, FailoverOptions{Window: 4, MinSuccessRate: 0.75, RecoverAfter: 2, ProbeInterval: time.Minute})
+	failover.now = func() time.Time { return now }
+
+	price, err := failover.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, "100", price.Bpi["USD"].Value.String())
+	require.Equal(t, []string{"primary"}, price.Sources)
+
+	// primary goes down: every call is served by the backup and primary gets demoted
+	primaryUp.Store(false)
+	price, err = failover.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, "200", price.Bpi["USD"].Value.String())
+	require.True(t, failover.Health()[0].Demoted)
+	require.Equal(t, 0.5, failover.Health()[0].SuccessRate)
+
+	// demoted primary recovers but isn't probed before the interval elapses
+	primaryUp.Store(true)
+	price, err = failover.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup"}, price.Sources)
+
+	// probes are due, primary answers twice in a row and gets promoted back
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Minute)
+		price, err = failover.GetPrice()
+		require.NoError(t, err)
+		require.Equal(t, []string{"primary"}, price.Sources)
+	}
+	require.False(t, failover.Health()[0].Demoted)
+
+	price, err = failover.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"primary"}, price.Sources)
+}
+
+func TestFailoverUsesDemotedAsLastResort(t *testing.T) {
+	var primaryUp, backupUp atomic.Bool
+	failover := NewFailoverProvider([]Source{
+		{Name: "primary", Provider: coinDeskStandIn(t, 100, &primaryUp)},
+		{Name: "backup", Provider: coinDeskStandIn(t, 200, &backupUp)},
+	}, FailoverOptions{Window: 2, MinSuccessRate: 0.5, RecoverAfter: 1, ProbeInterval: time.Hour})
+
+	_, err := failover.GetPrice()
+	require.Error(t, err)
+	require.True(t, failover.Health()[0].Demoted)
+	require.True(t, failover.Health()[1].Demoted)
+
+	backupUp.Store(true)
+	price, err := failover.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup"}, price.Sources)
+	require.False(t, failover.Health()[1].Demoted)
+}
+
+func TestFailoverRecoversWithWindowShorterThanStreak(t *testing.T) {
+	var primaryUp, backupUp atomic.Bool
+	backupUp.Store(true)
+	now := time.Unix(1706015736, 0)
+	failover := NewFailoverProvider([]Source{
+		{Name: "primary", Provider: coinDeskStandIn(t, 100, &primaryUp)},
+		{Name: "backup", Provider: coinDeskStandIn(t, 200, &backupUp)},
+	}, FailoverOptions{Window: 2, MinSuccessRate: 0.5, RecoverAfter: 3, ProbeInterval: time.Minute})
+	failover.now = func() time.Time { return now }
+
+	_, err := failover.GetPrice()
+	require.NoError(t, err)
+	require.True(t, failover.Health()[0].Demoted)
+
+	primaryUp.Store(true)
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Minute)
+		price, err := failover.GetPrice()
+		require.NoError(t, err)
+		require.Equal(t, []string{"primary"}, price.Sources)
+	}
+	require.False(t, failover.Health()[0].Demoted)
+	require.Equal(t, 1.0, failover.Health()[0].SuccessRate)
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+const defaultHTTPTimeout = 2 * time.Second
+
+// Timestamp formats supported by HTTPJSONConfig.TimestampFormat.
+const (
+	TimestampAuto    = ""
+	TimestampUnix    = "unix"
+	TimestampUnixMs  = "unix_ms"
+	TimestampRFC3339 = "rfc3339"
+)
+
+// HTTPJSONConfig describes a REST endpoint returning a JSON document holding the price.
+type HTTPJSONConfig struct {
+	// Asset is the symbol of the quoted asset, model.DefaultAsset when empty.
+	Asset   string            `json:"asset"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	// Timeout is a Go duration string, 2s by default.
+	Timeout string `json:"timeout"`
+	// TimestampPath points to the quote time, the time of the poll is used when empty.
+	TimestampPath   string `json:"timestamp_path"`
+	TimestampFormat string `json:"timestamp_format"`
+	// Rates maps a currency code onto the path of its rate, e.g. {"USD": "$.data.price"}.
+	Rates map[string]string `json:"rates"`
+}
+
+type httpJSONProvider struct {
+	url     string
+	headers map[string]string
+	timeout time.Duration
+	mapping *jsonMapping
+}
+
+// jsonMapping extracts a price out of a decoded JSON document.
+type jsonMapping struct {
+	asset           string
+	timestampPath   *jsonPath
+	timestampFormat string
+	rates           map[string]*jsonPath
+}
+
+func NewHTTPJSONProvider(cfg HTTPJSONConfig) (*httpJSONProvider, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("provider url is empty")
+	}
+
+	p := &httpJSONProvider{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		timeout: defaultHTTPTimeout,
+	}
+
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("wrong provider timeout %q: %w", cfg.Timeout, err)
+		}
+		p.timeout = timeout
+	}
+
+	mapping, err := newJSONMapping(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.mapping = mapping
+
+	return p, nil
+}
+
+func newJSONMapping(cfg HTTPJSONConfig) (*jsonMapping, error) {
+	m := &jsonMapping{
+		asset:           strings.ToUpper(cfg.Asset),
+		timestampFormat: cfg.TimestampFormat,
+		rates:           make(map[string]*jsonPath),
+	}
+
+	if m.asset == "" {
+		m.asset = model.DefaultAsset
+	}
+
+	if cfg.TimestampPath != "" {
+		path, err := compileJSONPath(cfg.TimestampPath)
+		if err != nil {
+			return nil, err
+		}
+		m.timestampPath = path
+	}
+
+	if len(cfg.Rates) == 0 {
+		return nil, fmt.Errorf("provider %s has no rates configured", cfg.URL)
+	}
+	for code, expr := range cfg.Rates {
+		code = strings.ToUpper(code)
+		if !model.IsKnownCurrency(code) {
+			return nil, fmt.Errorf("currency %s is not an ISO-4217 code", code)
+		}
+		path, err := compileJSONPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		m.rates[code] = path
+	}
+
+	return m, nil
+}
+
+func (p *httpJSONProvider) GetPrice() (*model.CurrentPrice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("provider returned wrong status code %d", res.StatusCode)
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return p.mapping.parse(doc)
+}
+
+func (m *jsonMapping) parse(doc interface{}) (*model.CurrentPrice, error) {
+	updated := time.Now()
+	if m.timestampPath != nil {
+		raw, err := m.timestampPath.lookup(doc)
+		if err != nil {
+			return nil, err
+		}
+		if updated, err = parseTimestamp(raw, m.timestampFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	price := &model.CurrentPrice{Asset: m.asset, Time: model.CurrentPriceTime{UpdatedISO: updated}}
+	for code, path := range m.rates {
+		raw, err := path.lookup(doc)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseDecimal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("wrong %s rate: %w", code, err)
+		}
+
+		value = model.RoundRate(code, value)
+		price.SetRate(code, model.CurrentPriceRate{
+			Code:  code,
+			Rate:  value.String(),
+			Value: value,
+		})
+	}
+	return price, nil
+}
+
+// parseDecimal keeps the exact digits of numbers and numeric strings.
+func parseDecimal(raw interface{}) (model.Decimal, error) {
+	switch v := raw.(type) {
+	case json.Number:
+		return model.ParseDecimal(v.String())
+	case string:
+		return model.ParseDecimal(v)
+	}
+	return model.Decimal{}, fmt.Errorf("value %v is not a number", raw)
+}
+
+func parseNumber(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	}
+	return 0, fmt.Errorf("value %v is not a number", raw)
+}
+
+func parseTimestamp(raw interface{}, format string) (time.Time, error) {
+	if s, ok := raw.(string); ok && (format == TimestampRFC3339 || format == TimestampAuto) {
+		if t, err := time.Parse(time.RFC3339, s); err == nil || format == TimestampRFC3339 {
+			return t, err
+		}
+	}
+
+	n, err := parseNumber(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("wrong timestamp: %w", err)
+	}
+	switch {
+	case format == TimestampUnixMs, format == TimestampAuto && n > 1e12:
+		return time.UnixMilli(int64(n)), nil
+	case format == TimestampUnix, format == TimestampAuto:
+		return time.Unix(int64(n), 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown timestamp format %q", format)
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPJSONProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{"data":{"ts":1706015736000,"quotes":[{"ccy":"USD","px":"41,234.56"},{"ccy":"EUR","px":37800.5}]}}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewHTTPJSONProvider(HTTPJSONConfig{
+		Asset:         "eth",
+		URL:           srv.URL,
+		Headers:       map[string]string{"X-Api-Key": "secret"},
+		Timeout:       "1s",
+		TimestampPath: "$.data.ts",
+		Rates: map[string]string{
+			"usd": "$.data.quotes[0].px",
+			"EUR": "data.quotes[-1]['px']",
+		},
+	})
+	require.NoError(t, err)
+
+	price, err := p.GetPrice()
+	require.NoError(t, err)
+	require.Equal(t, "ETH", price.Asset)
+	require.Equal(t, time.UnixMilli(1706015736000), price.Time.UpdatedISO)
+	require.Equal(t, "41234.56", price.Bpi["USD"].Value.String())
+	require.Equal(t, "USD", price.Bpi["USD"].Code)
+	require.Equal(t, "37800.5", price.Bpi["EUR"].Value.String())
+	require.NotContains(t, price.Bpi, "GBP")
+}
+
+func TestHTTPJSONProviderErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"price":{"usd":"n/a"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPJSONProvider(HTTPJSONConfig{URL: srv.URL, Rates: map[string]string{"USD": "$.price["}})
+	require.Error(t, err)
+
+	_, err = NewHTTPJSONProvider(HTTPJSONConfig{URL: srv.URL, Timeout: "soon", Rates: map[string]string{"USD": "$.price.usd"}})
+	require.Error(t, err)
+
+	p, err := NewHTTPJSONProvider(HTTPJSONConfig{URL: srv.URL, Rates: map[string]string{"USD": "$.price.usd"}})
+	require.NoError(t, err)
+	_, err = p.GetPrice()
+	require.Error(t, err)
+
+	p, err = NewHTTPJSONProvider(HTTPJSONConfig{URL: srv.URL, Rates: map[string]string{"USD": "$.price.eur"}})
+	require.NoError(t, err)
+	_, err = p.GetPrice()
+	require.Error(t, err)
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "providers.json")
+	err := os.WriteFile(path, []byte(`[
+		{"type": "coindesk"},
+		{"type": "http", "name": "exchange", "weight": 2, "url": "http://localhost/ticker", "rates": {"USD": "$.last"}}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	defs, err := LoadDefinitions(path)
+	require.NoError(t, err)
+	sources, err := NewSources(defs)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	require.Equal(t, "coindesk-0", sources[0].Name)
+	require.Equal(t, "exchange", sources[1].Name)
+	require.Equal(t, 2.0, sources[1].Weight)
+
+	_, err = NewSources([]Definition{{Type: "ftp"}})
+	require.Error(t, err)
+}
+
+func TestGroupByAsset(t *testing.T) {
+	assets, groups := GroupByAsset([]Definition{
+		{Name: "a"},
+		{Name: "b", HTTPJSONConfig: HTTPJSONConfig{Asset: "eth"}},
+		{Name: "c", HTTPJSONConfig: HTTPJSONConfig{Asset: "BTC"}},
+	})
+	require.Equal(t, []string{"BTC", "ETH"}, assets)
+	require.Len(t, groups["BTC"], 2)
+	require.Len(t, groups["ETH"], 1)
+
+	_, err := NewSources([]Definition{{Type: TypeCoinDesk, HTTPJSONConfig: HTTPJSONConfig{Asset: "ETH"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type pathStep struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// jsonPath is a compiled subset of JSONPath: `$`, `.key`, `['key']` and `[index]` steps.
+type jsonPath struct {
+	expr  string
+	steps []pathStep
+}
+
+func compileJSONPath(expr string) (*jsonPath, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+	path := &jsonPath{expr: expr}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("json path %q: empty key", expr)
+			}
+			path.steps = append(path.steps, pathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q: unclosed bracket", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				path.steps = append(path.steps, pathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("json path %q: wrong index %q", expr, inner)
+			}
+			path.steps = append(path.steps, pathStep{index: idx, isIdx: true})
+		default:
+			// allow paths written without the leading `$.`
+			if len(path.steps) == 0 {
+				rest = "." + rest
+				continue
+			}
+			return nil, fmt.Errorf("json path %q: unexpected %q", expr, rest)
+		}
+	}
+	return path, nil
+}
+
+// lookup walks a document decoded with json.Decoder.UseNumber.
+func (p *jsonPath) lookup(doc interface{}) (interface{}, error) {
+	cur := doc
+	for _, step := range p.steps {
+		if step.isIdx {
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %q: not an array", p.expr)
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, fmt.Errorf("json path %q: index %d out of range", p.expr, step.index)
+			}
+			cur = arr[idx]
+			continue
+		}
+
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("json path %q: not an object", p.expr)
+		}
+		if cur, ok = obj[step.key]; !ok {
+			return nil, fmt.Errorf("json path %q: key %q not found", p.expr, step.key)
+		}
+	}
+	return cur, nil
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = 30 * time.Second
+)
+
+// WebsocketConfig describes an upstream pushing ticks over a websocket.
+// URL, headers, timestamp and rate paths have the same meaning as for HTTP providers.
+type WebsocketConfig struct {
+	HTTPJSONConfig
+	// Subscribe is sent as a text frame right after every (re)connection.
+	Subscribe string
+	// ReconnectDelay is the initial backoff, doubled after each failed attempt up to MaxReconnectDelay.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+}
+
+type websocketProvider struct {
+	url               string
+	headers           http.Header
+	subscribe         string
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	mapping           *jsonMapping
+}
+
+func NewWebsocketProvider(cfg WebsocketConfig) (*websocketProvider, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("provider url is empty")
+	}
+
+	mapping, err := newJSONMapping(cfg.HTTPJSONConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	for k, v := range cfg.Headers {
+		headers.Set(k, v)
+	}
+
+	p := &websocketProvider{
+		url:               cfg.URL,
+		headers:           headers,
+		subscribe:         cfg.Subscribe,
+		reconnectDelay:    cfg.ReconnectDelay,
+		maxReconnectDelay: cfg.MaxReconnectDelay,
+		mapping:           mapping,
+	}
+	if p.reconnectDelay <= 0 {
+		p.reconnectDelay = defaultReconnectDelay
+	}
+	if p.maxReconnectDelay < p.reconnectDelay {
+		p.maxReconnectDelay = defaultMaxReconnectDelay
+	}
+	return p, nil
+}
+
+// Stream keeps an upstream session open until ctx is cancelled, reconnecting with exponential backoff.
+func (p *websocketProvider) Stream(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+	delay := p.reconnectDelay
+	for {
+		received, err := p.session(ctx, prices)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if received {
+			delay = p.reconnectDelay
+		}
+		log.Err(err).Msgf("websocket provider %s disconnected, reconnecting in %s", p.url, delay)
+		select {
+		case errors <- err:
+		case <-ctx.Done():
+			return nil
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		}
+		delay *= 2
+		if delay > p.maxReconnectDelay {
+			delay = p.maxReconnectDelay
+		}
+	}
+}
+
+// session reads ticks from a single connection, it reports whether at least one tick was received.
+func (p *websocketProvider) session(ctx context.Context, prices chan<- *model.CurrentPrice) (bool, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, p.url, p.headers)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// unblock ReadMessage when the caller is gone
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if p.subscribe != "" {
+		if err = conn.WriteMessage(websocket.TextMessage, []byte(p.subscribe)); err != nil {
+			return false, err
+		}
+	}
+
+	received := false
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader(message))
+		decoder.UseNumber()
+		if err = decoder.Decode(&doc); err != nil {
+			log.Debug().Msgf("skipping non JSON frame from %s", p.url)
+			continue
+		}
+
+		// acks, heartbeats and other control frames don't match the mapping
+		price, err := p.mapping.parse(doc)
+		if err != nil {
+			log.Debug().Msgf("skipping frame from %s: %v", p.url, err)
+			continue
+		}
+
+		received = true
+		select {
+		case prices <- price:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
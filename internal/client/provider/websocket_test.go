@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocketProviderReconnects(t *testing.T) {
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		n := connections.Add(1)
+
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, `{"op":"subscribe","channel":"ticker"}`, string(msg))
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"subscribed"}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`not json`))
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"ts":%d,"last":"%d.5"}`, 1706015736+n, 100*n)))
+		// dropping the session forces the provider to reconnect
+	}))
+	defer srv.Close()
+
+	p, err := NewWebsocketProvider(WebsocketConfig{
+		HTTPJSONConfig: HTTPJSONConfig{
+			URL:           "ws" + strings.TrimPrefix(srv.URL, "http"),
+			TimestampPath: "$.ts",
+			Rates:         map[string]string{"USD": "$.last"},
+		},
+		Subscribe:      `{"op":"subscribe","channel":"ticker"}`,
+		ReconnectDelay: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	prices := make(chan *model.CurrentPrice)
+	errs := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		require.NoError(t, p.Stream(ctx, prices, errs))
+		close(done)
+	}()
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case price := <-prices:
+			require.Equal(t, fmt.Sprintf("%d.5", 100*i), price.Bpi["USD"].Value.String())
+			require.Equal(t, time.Unix(int64(1706015736+i), 0), price.Time.UpdatedISO)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no tick received")
+		}
+	}
+	require.GreaterOrEqual(t, connections.Load(), int32(2))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream didn't stop")
+	}
+}
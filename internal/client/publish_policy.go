@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+// RoundSource returns the last round published for an asset, so rounds keep increasing across restarts.
+type RoundSource func(ctx context.Context, asset string) (uint64, error)
+
+// PublishPolicy publishes a price only when it moved more than DeviationBps basis points
+// from the last published one or when Heartbeat elapsed since then, like on-chain oracles do.
+// Zero values disable the corresponding trigger.
+type PublishPolicy struct {
+	DeviationBps float64
+	Heartbeat    time.Duration
+}
+
+type publishedRound struct {
+	round       uint64
+	price       *model.CurrentPrice
+	publishedAt time.Time
+}
+
+type Publisher struct {
+	policy    PublishPolicy
+	lastRound RoundSource
+	now       func() time.Time
+
+	mutex sync.Mutex
+	last  map[string]*publishedRound
+}
+
+func NewPublisher(policy PublishPolicy, lastRound RoundSource) *Publisher {
+	return &Publisher{
+		policy:    policy,
+		lastRound: lastRound,
+		now:       time.Now,
+		last:      make(map[string]*publishedRound),
+	}
+}
+
+// Next returns the round price is published under, false when the policy holds it back.
+func (p *Publisher) Next(ctx context.Context, price *model.CurrentPrice) (uint64, bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	asset := price.AssetSymbol()
+	last, ok := p.last[asset]
+	if !ok {
+		round, err := p.lastRound(ctx, asset)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read last round of %s: %w", asset, err)
+		}
+		last = &publishedRound{round: round}
+		p.last[asset] = last
+	}
+
+	now := p.now()
+	if last.price != nil && !p.heartbeatDue(last, now) && !p.deviates(last.price, price) {
+		return 0, false, nil
+	}
+
+	last.round++
+	last.price = price
+	last.publishedAt = now
+	return last.round, true, nil
+}
+
+func (p *Publisher) heartbeatDue(last *publishedRound, now time.Time) bool {
+	return p.policy.Heartbeat > 0 && now.Sub(last.publishedAt) >= p.policy.Heartbeat
+}
+
+// deviates reports whether any rate moved more than DeviationBps, a currency appearing or
+// disappearing counts as a move.
+func (p *Publisher) deviates(last, price *model.CurrentPrice) bool {
+	if len(last.Bpi) != len(price.Bpi) {
+		return true
+	}
+	if p.policy.DeviationBps <= 0 {
+		return false
+	}
+	bps, err := model.DecimalFromFloat(p.policy.DeviationBps)
+	if err != nil {
+		return true
+	}
+
+	for code, rate := range price.Bpi {
+		prev, ok := last.Bpi[code]
+		if !ok {
+			return true
+		}
+		if prev.Value.Sign() <= 0 {
+			continue
+		}
+		// |rate - prev| / prev * 10000 > bps, kept exact by multiplying instead of dividing
+		moved := rate.Value.Sub(prev.Value)
+		if moved.Sign() < 0 {
+			moved = prev.Value.Sub(rate.Value)
+		}
+		if moved.Mul(model.NewDecimal(10000, 0)).Cmp(prev.Value.Mul(bps)) > 0 {
+			return true
+		}
+	}
+	return false
+}
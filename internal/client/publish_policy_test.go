@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisherDeviationAndHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1706015736, 0)
+	lastRounds := map[string]uint64{"BTC": 41}
+	publisher := NewPublisher(PublishPolicy{DeviationBps: 50, Heartbeat: time.Hour}, func(ctx context.Context, asset string) (uint64, error) {
+		return lastRounds[asset], nil
+	})
+	publisher.now = func() time.Time { return now }
+
+	// the first price of an asset is always published, continuing from the stored round
+	round, ok, err := publisher.Next(ctx, tickAt(now, 40000))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(42), round)
+
+	// 0.5% is exactly 50 bps, not more
+	_, ok, err = publisher.Next(ctx, tickAt(now, 40200))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	round, ok, _ = publisher.Next(ctx, tickAt(now, 40201))
+	require.True(t, ok)
+	require.Equal(t, uint64(43), round)
+
+	// deviation is measured from the last published price, not the last tick
+	_, ok, _ = publisher.Next(ctx, tickAt(now, 40100))
+	require.False(t, ok)
+
+	now = now.Add(time.Hour)
+	round, ok, _ = publisher.Next(ctx, tickAt(now, 40201))
+	require.True(t, ok)
+	require.Equal(t, uint64(44), round)
+
+	eth := tickAt(now, 2000)
+	eth.Asset = "ETH"
+	round, ok, _ = publisher.Next(ctx, eth)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), round)
+}
+
+func TestPublisherRetriesLastRound(t *testing.T) {
+	calls := 0
+	publisher := NewPublisher(PublishPolicy{DeviationBps: 10}, func(ctx context.Context, asset string) (uint64, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("db is down")
+		}
+		return 5, nil
+	})
+
+	_, ok, err := publisher.Next(context.Background(), tickAt(time.Now(), 100))
+	require.Error(t, err)
+	require.False(t, ok)
+
+	round, ok, err := publisher.Next(context.Background(), tickAt(time.Now(), 100))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(6), round)
+}
+
+func TestFetcherSkipsUnchangedPrices(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := tickAt(time.Now(), 40000)
+	same := tickAt(time.Now(), 40001)
+	moved := tickAt(time.Now(), 41000)
+	stream := streamFunc(func(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+		prices <- first
+		prices <- same
+		prices <- moved
+		<-ctx.Done()
+		return nil
+	})
+
+	receiver := make(chan *model.CurrentPrice)
+	errs := make(chan error)
+	publisher := NewPublisher(PublishPolicy{DeviationBps: 50}, func(ctx context.Context, asset string) (uint64, error) {
+		return 0, nil
+	})
+	go NewStreamFetcher(nil, stream, false).WithPublisher(publisher).RunStreamFetcher(ctx, receiver, errs)
+
+	var rounds []uint64
+	for len(rounds) < 2 {
+		select {
+		case price := <-receiver:
+			require.NotEqual(t, same, price)
+			rounds = append(rounds, price.Round)
+		case <-time.After(5 * time.Second):
+			t.Fatal("price wasn't forwarded")
+		}
+	}
+	require.ElementsMatch(t, []uint64{1, 2}, rounds)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+// Bounds is the accepted range of a rate, zero Max means no upper bound.
+type Bounds struct {
+	Min float64
+	Max float64
+}
+
+// ValidationRules configures the checks run on every tick before it is saved or broadcast.
+// Zero values disable the corresponding rule, non-positive rates are always rejected.
+type ValidationRules struct {
+	// MaxDeviation is the highest accepted distance from the rolling median, as a fraction.
+	MaxDeviation float64
+	// Window is the number of accepted ticks the rolling median is computed on.
+	Window int
+	// Bounds are keyed by "ASSET/CURRENCY", e.g. "BTC/USD".
+	Bounds map[string]Bounds
+	// Monotonic rejects ticks older than the last accepted tick of the same asset.
+	Monotonic bool
+	// MaxAge rejects ticks whose timestamp is older than now - MaxAge.
+	MaxAge time.Duration
+}
+
+type assetHistory struct {
+	rates      map[string][]float64
+	lastUpdate time.Time
+	// deviations counts consecutive ticks rejected for deviation, after a full window
+	// of them the market is considered to have moved and the median is re-anchored
+	deviations int
+}
+
+type Validator struct {
+	rules ValidationRules
+	now   func() time.Time
+
+	mutex   sync.Mutex
+	history map[string]*assetHistory
+}
+
+func NewValidator(rules ValidationRules) *Validator {
+	if rules.Window < 1 {
+		rules.Window = 1
+	}
+	return &Validator{
+		rules:   rules,
+		now:     time.Now,
+		history: make(map[string]*assetHistory),
+	}
+}
+
+// ParseBounds reads bounds written as {"BTC/USD": "1000-1000000"}.
+func ParseBounds(raw map[string]string) (map[string]Bounds, error) {
+	bounds := make(map[string]Bounds, len(raw))
+	for pair, value := range raw {
+		min, max, ok := strings.Cut(value, "-")
+		if !ok {
+			return nil, fmt.Errorf("wrong bounds %q for %s, expected min-max", value, pair)
+		}
+
+		var b Bounds
+		var err error
+		if b.Min, err = strconv.ParseFloat(strings.TrimSpace(min), 64); err != nil {
+			return nil, fmt.Errorf("wrong lower bound for %s: %w", pair, err)
+		}
+		if strings.TrimSpace(max) != "" {
+			if b.Max, err = strconv.ParseFloat(strings.TrimSpace(max), 64); err != nil {
+				return nil, fmt.Errorf("wrong upper bound for %s: %w", pair, err)
+			}
+		}
+		bounds[strings.ToUpper(pair)] = b
+	}
+	return bounds, nil
+}
+
+// Validate returns an error describing why price was rejected, accepted ticks are added to the history.
+func (v *Validator) Validate(price *model.CurrentPrice) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	asset := price.AssetSymbol()
+	history, ok := v.history[asset]
+	if !ok {
+		history = &assetHistory{rates: make(map[string][]float64)}
+		v.history[asset] = history
+	}
+
+	updated := price.Time.UpdatedISO
+	if v.rules.MaxAge > 0 && v.now().Sub(updated) > v.rules.MaxAge {
+		return fmt.Errorf("stale price: updated at %s, older than %s", updated.Format(time.RFC3339), v.rules.MaxAge)
+	}
+	if v.rules.Monotonic && updated.Before(history.lastUpdate) {
+		return fmt.Errorf("price timestamp %s goes back from %s", updated.Format(time.RFC3339), history.lastUpdate.Format(time.RFC3339))
+	}
+
+	if len(price.Bpi) == 0 {
+		return fmt.Errorf("price has no rates")
+	}
+
+	deviated := false
+	var deviationErr error
+	for _, code := range price.Currencies() {
+		value := price.Bpi[code].Value
+		if value.Sign() <= 0 {
+			return fmt.Errorf("%s rate %s is not positive", code, value)
+		}
+		if precision := model.DecimalSpecFor(code).Precision; value.Digits() > precision {
+			return fmt.Errorf("%s rate %s exceeds %d digits", code, value, precision)
+		}
+		// bounds and deviation are tolerances, a float is precise enough for them
+		rate := value.Float64()
+		if b, ok := v.rules.Bounds[asset+"/"+code]; ok && (rate < b.Min || (b.Max > 0 && rate > b.Max)) {
+			return fmt.Errorf("%s rate %v is out of bounds [%v, %v]", code, rate, b.Min, b.Max)
+		}
+
+		window := history.rates[code]
+		if v.rules.MaxDeviation <= 0 || len(window) == 0 {
+			continue
+		}
+		median := medianOf(window)
+		if deviation := math.Abs(rate-median) / median; deviation > v.rules.MaxDeviation {
+			deviated = true
+			deviationErr = fmt.Errorf("%s rate %v deviates %.2f%% from rolling median %v", code, rate, deviation*100, median)
+		}
+	}
+
+	if deviated {
+		history.deviations++
+		if history.deviations < v.rules.Window {
+			return deviationErr
+		}
+		// every tick of a full window disagreed with the median: start over from the new level
+		history.rates = make(map[string][]float64)
+	}
+
+	history.deviations = 0
+	if updated.After(history.lastUpdate) {
+		history.lastUpdate = updated
+	}
+	for code, rate := range price.Bpi {
+		window := append(history.rates[code], rate.Value.Float64())
+		if len(window) > v.rules.Window {
+			window = window[len(window)-v.rules.Window:]
+		}
+		history.rates[code] = window
+	}
+	return nil
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func tickAt(at time.Time, usd int64) *model.CurrentPrice {
+	return &model.CurrentPrice{
+		Asset: "BTC",
+		Time:  model.CurrentPriceTime{UpdatedISO: at},
+		Bpi:   map[string]model.CurrentPriceRate{"USD": {Code: "USD", Value: model.NewDecimal(usd, 0)}},
+	}
+}
+
+func TestValidatorRules(t *testing.T) {
+	bounds, err := ParseBounds(map[string]string{"btc/usd": "1000-1000000"})
+	require.NoError(t, err)
+	_, err = ParseBounds(map[string]string{"BTC/USD": "1000"})
+	require.Error(t, err)
+
+	now := time.Unix(1706015736, 0)
+	v := NewValidator(ValidationRules{
+		MaxDeviation: 0.1,
+		Window:       3,
+		Bounds:       bounds,
+		Monotonic:    true,
+		MaxAge:       time.Minute,
+	})
+	v.now = func() time.Time { return now }
+
+	require.NoError(t, v.Validate(tickAt(now, 40000)))
+	require.Error(t, v.Validate(tickAt(now, 0)))
+	require.Error(t, v.Validate(tickAt(now, -1)))
+	require.Error(t, v.Validate(tickAt(now, 999)), "below bounds")
+	require.Error(t, v.Validate(tickAt(now.Add(-2*time.Minute), 40000)), "stale")
+	require.Error(t, v.Validate(tickAt(now.Add(-time.Second), 40000)), "goes back in time")
+	require.NoError(t, v.Validate(tickAt(now, 41000)), "same timestamp is fine")
+	require.Error(t, v.Validate(tickAt(now, 60000)), "50% spike")
+
+	// other assets have their own history
+	eth := tickAt(now.Add(-time.Second), 2000)
+	eth.Asset = "ETH"
+	require.NoError(t, v.Validate(eth))
+}
+
+func TestValidatorFollowsSustainedMove(t *testing.T) {
+	now := time.Unix(1706015736, 0)
+	v := NewValidator(ValidationRules{MaxDeviation: 0.1, Window: 3})
+	v.now = func() time.Time { return now }
+
+	require.NoError(t, v.Validate(tickAt(now, 40000)))
+	require.Error(t, v.Validate(tickAt(now, 60000)))
+	require.Error(t, v.Validate(tickAt(now, 60100)))
+	// a whole window disagreeing with the median means the market moved
+	require.NoError(t, v.Validate(tickAt(now, 60200)))
+	require.NoError(t, v.Validate(tickAt(now, 60300)))
+	require.Error(t, v.Validate(tickAt(now, 40000)))
+}
@@ -0,0 +1,195 @@
+// Package fx derives prices in currencies providers don't quote using FX reference rates.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// preferredBase is used first when several quoted currencies could derive the same target.
+const preferredBase = "USD"
+
+// inverseScale is the number of digits kept when a rate is only known the other way round.
+const inverseScale = 18
+
+// Table holds FX reference rates, rates[base][quote] is the amount of quote for one base.
+type Table struct {
+	mutex sync.RWMutex
+	rates map[string]map[string]model.Decimal
+}
+
+func NewTable() *Table {
+	return &Table{rates: make(map[string]map[string]model.Decimal)}
+}
+
+// LoadTable reads a JSON object like {"USD": {"JPY": 148.2, "CHF": 0.86}}.
+func LoadTable(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]map[string]model.Decimal
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("wrong fx table %s: %w", path, err)
+	}
+
+	table := NewTable()
+	for base, quotes := range raw {
+		for quote, rate := range quotes {
+			if err = table.Set(base, quote, rate); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return table, nil
+}
+
+// Set stores the rate converting base into quote.
+func (t *Table) Set(base, quote string, rate model.Decimal) error {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if !model.IsKnownCurrency(base) || !model.IsKnownCurrency(quote) {
+		return fmt.Errorf("wrong fx pair %s/%s", base, quote)
+	}
+	if rate.Sign() <= 0 {
+		return fmt.Errorf("fx rate %s/%s must be positive", base, quote)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.rates[base] == nil {
+		t.rates[base] = make(map[string]model.Decimal)
+	}
+	t.rates[base][quote] = rate
+	return nil
+}
+
+// Rate converts one unit of from into to, inverse rates are used when only to/from is known.
+func (t *Table) Rate(from, to string) (model.Decimal, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if rate, ok := t.rates[from][to]; ok {
+		return rate, true
+	}
+	if rate, ok := t.rates[to][from]; ok {
+		return model.NewDecimal(1, 0).Quo(rate, inverseScale), true
+	}
+	return model.Decimal{}, false
+}
+
+// Currencies returns every currency the table can convert to or from, sorted.
+func (t *Table) Currencies() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	for base, quotes := range t.rates {
+		seen[base] = true
+		for quote := range quotes {
+			seen[quote] = true
+		}
+	}
+
+	codes := make([]string, 0, len(seen))
+	for code := range seen {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+type CrossRates struct {
+	table *Table
+}
+
+func NewCrossRates(table *Table) *CrossRates {
+	return &CrossRates{table: table}
+}
+
+// Apply returns a copy of price completed with every currency derivable from the table.
+// Quoted rates are never overwritten, derived ones are flagged with Derived.
+func (c *CrossRates) Apply(price *model.CurrentPrice) *model.CurrentPrice {
+	res := *price
+	res.Bpi = make(map[string]model.CurrentPriceRate, len(price.Bpi))
+	for code, rate := range price.Bpi {
+		res.Bpi[code] = rate
+	}
+
+	bases := quotedBases(price)
+	for _, target := range c.table.Currencies() {
+		if _, ok := res.Bpi[target]; ok {
+			continue
+		}
+		for _, base := range bases {
+			fx, ok := c.table.Rate(base, target)
+			if !ok {
+				continue
+			}
+			value := model.RoundRate(target, price.Bpi[base].Value.Mul(fx))
+			res.Bpi[target] = model.CurrentPriceRate{
+				Code:        target,
+				Rate:        value.String(),
+				Description: "derived from " + base,
+				Value:       value,
+				Derived:     true,
+			}
+			break
+		}
+	}
+	return &res
+}
+
+// Sources returns the currencies a price has to quote for Apply to complete it with currency, currency first.
+func (c *CrossRates) Sources(currency string) []string {
+	currency = strings.ToUpper(currency)
+	sources := []string{currency}
+	for _, base := range c.table.Currencies() {
+		if _, ok := c.table.Rate(base, currency); ok && base != currency {
+			sources = append(sources, base)
+		}
+	}
+	return sources
+}
+
+// Run completes every price read from in and forwards it to out until ctx is done.
+func (c *CrossRates) Run(ctx context.Context, in <-chan *model.CurrentPrice, out chan<- *model.CurrentPrice) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case price := <-in:
+			select {
+			case out <- c.Apply(price):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// quotedBases returns currencies quoted by providers, preferredBase first.
+func quotedBases(price *model.CurrentPrice) []string {
+	var bases []string
+	for _, code := range price.Currencies() {
+		rate := price.Bpi[code]
+		if rate.Derived || rate.Value.Sign() <= 0 {
+			continue
+		}
+		if code == preferredBase {
+			bases = append([]string{code}, bases...)
+			continue
+		}
+		bases = append(bases, code)
+	}
+	return bases
+}
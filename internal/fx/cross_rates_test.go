@@ -0,0 +1,69 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossRatesApply(t *testing.T) {
+	table := NewTable()
+	require.NoError(t, table.Set("USD", "JPY", model.NewDecimal(150, 0)))
+	require.NoError(t, table.Set("chf", "usd", model.MustParseDecimal("1.25")))
+	require.NoError(t, table.Set("USD", "EUR", model.MustParseDecimal("0.5")))
+	require.Error(t, table.Set("USD", "XYZ", model.NewDecimal(1, 0)))
+	require.Error(t, table.Set("USD", "SEK", model.Decimal{}))
+
+	price := &model.CurrentPrice{
+		Asset: "BTC",
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Code: "USD", Value: model.MustParseDecimal("40000")},
+			"EUR": {Code: "EUR", Value: model.MustParseDecimal("37000")},
+		},
+	}
+	res := NewCrossRates(table).Apply(price)
+
+	require.Equal(t, "6000000", res.Bpi["JPY"].Value.String())
+	require.True(t, res.Bpi["JPY"].Derived)
+	require.Equal(t, "32000", res.Bpi["CHF"].Value.String())
+	require.True(t, res.Bpi["CHF"].Derived)
+
+	// quoted rates win over derived ones
+	require.Equal(t, "37000", res.Bpi["EUR"].Value.String())
+	require.False(t, res.Bpi["EUR"].Derived)
+
+	// the input is left untouched since it is shared with storage
+	require.Len(t, price.Bpi, 2)
+
+	// stored prices quoting a source are completed with the currency
+	crossRates := NewCrossRates(table)
+	require.Equal(t, []string{"JPY", "USD"}, crossRates.Sources("jpy"))
+	require.Equal(t, []string{"USD", "CHF", "EUR", "JPY"}, crossRates.Sources("USD"))
+	require.Equal(t, []string{"SEK"}, crossRates.Sources("SEK"))
+}
+
+func TestCrossRatesRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fx.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD": {"JPY": 100}}`), 0o600))
+	table, err := LoadTable(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan *model.CurrentPrice)
+	out := make(chan *model.CurrentPrice)
+	go NewCrossRates(table).Run(ctx, in, out)
+
+	in <- &model.CurrentPrice{Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.MustParseDecimal("2")}}}
+	select {
+	case price := <-out:
+		require.Equal(t, "200", price.Bpi["JPY"].Value.String())
+	case <-time.After(time.Second):
+		t.Fatal("price wasn't forwarded")
+	}
+}
@@ -0,0 +1,264 @@
+// Package hub fans prices out to subscribers of every transport.
+package hub
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const queueBufferSize = 100
+
+// Filter selects the prices a subscriber receives, empty lists match everything.
+type Filter struct {
+	Assets     []string
+	Currencies []string
+	// MatchNone keeps the subscription open without delivering anything, e.g. once a client unsubscribed from every asset.
+	MatchNone bool
+	// MatchNoCurrency delivers nothing either, once a client unsubscribed from every currency.
+	MatchNoCurrency bool
+}
+
+// Matches reports whether price belongs to one of Assets and quotes at least one of Currencies.
+func (f Filter) Matches(price *model.CurrentPrice) bool {
+	if f.matchesNothing() || !price.MatchesAssets(f.Assets) {
+		return false
+	}
+	if len(f.Currencies) == 0 {
+		return true
+	}
+	for _, code := range f.Currencies {
+		if _, ok := price.Rate(code); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matchesNothing() bool {
+	return f.MatchNone || f.MatchNoCurrency
+}
+
+type Hub struct {
+	source <-chan *model.CurrentPrice
+	errors <-chan error
+	repo   repository.Prices
+	// replayTransform completes stored prices the same way the pipeline completes live ones
+	replayTransform func(*model.CurrentPrice) *model.CurrentPrice
+	// currencySources returns the stored currencies the replay transform derives a currency from
+	currencySources func(currency string) []string
+	// policy applies to subscriptions not choosing their own
+	policy Policy
+
+	mutex       sync.RWMutex
+	subscribers map[string]*Subscription
+}
+
+// New returns a hub delivering prices read from source, errors are drained and logged.
+func New(source <-chan *model.CurrentPrice, errors <-chan error, repo repository.Prices) *Hub {
+	return &Hub{
+		source:      source,
+		errors:      errors,
+		repo:        repo,
+		subscribers: make(map[string]*Subscription),
+		policy:      PolicyDropOldest,
+	}
+}
+
+// WithPolicy sets the slow consumer policy of subscriptions not choosing their own.
+func (h *Hub) WithPolicy(policy Policy) *Hub {
+	if policy != "" {
+		h.policy = policy
+	}
+	return h
+}
+
+// WithReplayTransform applies transform to every replayed price, e.g. FX derivation and signing.
+func (h *Hub) WithReplayTransform(transform func(*model.CurrentPrice) *model.CurrentPrice) *Hub {
+	h.replayTransform = transform
+	return h
+}
+
+// Complete applies the replay transform to a stored price, for transports serving stored prices on their own.
+func (h *Hub) Complete(price *model.CurrentPrice) *model.CurrentPrice {
+	if h.replayTransform == nil {
+		return price
+	}
+	return h.replayTransform(price)
+}
+
+// WithCurrencySources tells which stored currencies the replay transform derives a currency from, so stores can be
+// queried for the prices quoting a currency once completed.
+func (h *Hub) WithCurrencySources(sources func(currency string) []string) *Hub {
+	h.currencySources = sources
+	return h
+}
+
+// StoredCurrencies returns the currencies a stored price has to quote one of to quote one of currencies once
+// completed, nil when any price does.
+func (h *Hub) StoredCurrencies(currencies []string) []string {
+	var stored []string
+	seen := make(map[string]bool)
+	for _, currency := range currencies {
+		sources := []string{strings.ToUpper(currency)}
+		if h.currencySources != nil {
+			sources = h.currencySources(currency)
+		}
+		for _, code := range sources {
+			if !seen[code] {
+				seen[code] = true
+				stored = append(stored, code)
+			}
+		}
+	}
+	return stored
+}
+
+// Subscribe registers a subscription receiving prices matching filter until ctx is done or it is closed.
+// An empty policy means the hub default.
+func (h *Hub) Subscribe(ctx context.Context, filter Filter, policy Policy) *Subscription {
+	if policy == "" {
+		policy = h.policy
+	}
+	sub := newSubscription(h, filter, policy)
+
+	h.mutex.Lock()
+	h.subscribers[sub.id] = sub
+	h.mutex.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Close()
+		case <-sub.done:
+		}
+	}()
+	return sub
+}
+
+// remove drops the subscription from the fan-out and closes its queue. Publish holds the read lock
+// while sending, so the queue is never closed under a writer.
+func (h *Hub) remove(sub *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.subscribers, sub.id)
+	close(sub.queue)
+}
+
+// Replay returns the stored prices created after since which match the subscription filter,
+// see handoff for how they line up with the live prices of the subscription.
+func (h *Hub) Replay(ctx context.Context, sub *Subscription, since time.Time) ([]*model.CurrentPrice, error) {
+	sub.buffer()
+	filter := sub.Filter()
+	if filter.matchesNothing() {
+		return h.handoff(sub, nil), nil
+	}
+	stored, err := h.repo.GetSinceDate(ctx, since, filter.Assets)
+	// the live prices buffered meanwhile are returned even when the query failed
+	return h.handoff(sub, stored), err
+}
+
+// Resume returns the stored prices after seq which match the subscription filter, see handoff for
+// how they line up with the live prices of the subscription.
+func (h *Hub) Resume(ctx context.Context, sub *Subscription, seq uint64) ([]*model.CurrentPrice, error) {
+	sub.buffer()
+	filter := sub.Filter()
+	if filter.matchesNothing() {
+		return h.handoff(sub, nil), nil
+	}
+	stored, err := h.repo.GetSinceSeq(ctx, seq, filter.Assets)
+	return h.handoff(sub, stored), err
+}
+
+// handoff merges stored prices with the live ones the subscription buffered while they were queried,
+// however many, without duplicates and in timestamp order. The subscription then only delivers what
+// comes after, so a client sending the result and then reading the subscription sees every update once.
+func (h *Hub) handoff(sub *Subscription, stored []*model.CurrentPrice) []*model.CurrentPrice {
+	filter := sub.Filter()
+	var replayed []*model.CurrentPrice
+	for _, price := range stored {
+		price = h.Complete(price)
+		if filter.Matches(price) {
+			replayed = append(replayed, price)
+		}
+	}
+
+	// like Publish, so the queue isn't closed meanwhile
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if _, ok := h.subscribers[sub.id]; !ok {
+		return sortByTime(replayed)
+	}
+	return sortByTime(sub.takeOver(replayed))
+}
+
+// Publish queues price for every matching subscriber. It never blocks, subscribers whose queue
+// is full are handled according to their policy.
+func (h *Hub) Publish(price *model.CurrentPrice) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for _, sub := range h.subscribers {
+		if !sub.filter.Matches(price) {
+			continue
+		}
+		sub.offer(price)
+	}
+}
+
+// Run delivers prices from the source until ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case price, ok := <-h.source:
+			if !ok {
+				return
+			}
+			log.Info().Msgf("received rate %v", price)
+			h.Publish(price)
+		case err := <-h.errors:
+			if err != nil {
+				log.Err(err).Msgf("something goes wrong with channel %s", err)
+			}
+		}
+	}
+}
+
+// sortByTime orders prices by timestamp, prices stored at the same time by sequence number.
+func sortByTime(prices []*model.CurrentPrice) []*model.CurrentPrice {
+	sort.SliceStable(prices, func(i, j int) bool {
+		ti, tj := prices[i].Time.UpdatedISO, prices[j].Time.UpdatedISO
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return prices[i].Seq < prices[j].Seq
+	})
+	return prices
+}
+
+// Stats returns the queue of every subscriber, ordered by ID.
+func (h *Hub) Stats() []SubscriptionStats {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	res := make([]SubscriptionStats, 0, len(h.subscribers))
+	for _, sub := range h.subscribers {
+		res = append(res, sub.stats())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// Count returns the number of subscribers.
+func (h *Hub) Count() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.subscribers)
+}
@@ -0,0 +1,322 @@
+package hub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func price(asset string, currencies ...string) *model.CurrentPrice {
+	p := &model.CurrentPrice{Asset: asset, Time: model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0)}}
+	for _, code := range currencies {
+		p.SetRate(code, model.CurrentPriceRate{Code: code, Value: model.NewDecimal(1, 0)})
+	}
+	return p
+}
+
+func receive(t *testing.T, queue <-chan *model.CurrentPrice) *model.CurrentPrice {
+	select {
+	case p := <-queue:
+		return p
+	case <-time.After(time.Second):
+		t.Fatal("price wasn't delivered")
+	}
+	return nil
+}
+
+func requireEmpty(t *testing.T, queue <-chan *model.CurrentPrice) {
+	select {
+	case p := <-queue:
+		t.Fatalf("unexpected price %v", p)
+	default:
+	}
+}
+
+func TestHubFiltersByTopic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := make(chan *model.CurrentPrice)
+	h := New(source, make(chan error), nil)
+	go h.Run(ctx)
+
+	all := h.Subscribe(ctx, Filter{}, "").C()
+	eth := h.Subscribe(ctx, Filter{Assets: []string{"eth"}}, "").C()
+	jpy := h.Subscribe(ctx, Filter{Currencies: []string{"jpy"}}, "").C()
+
+	btc := price("BTC", "USD")
+	source <- btc
+	require.Equal(t, btc, receive(t, all))
+
+	ethJPY := price("ETH", "USD", "JPY")
+	source <- ethJPY
+	require.Equal(t, ethJPY, receive(t, all))
+	require.Equal(t, ethJPY, receive(t, eth))
+	require.Equal(t, ethJPY, receive(t, jpy))
+	requireEmpty(t, eth)
+	requireEmpty(t, jpy)
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	h := New(nil, nil, nil)
+	sub := h.Subscribe(context.Background(), Filter{}, "")
+	other := h.Subscribe(context.Background(), Filter{}, "")
+	require.NotEqual(t, sub.ID(), other.ID())
+	require.Equal(t, 2, h.Count())
+
+	sub.Close()
+	sub.Close()
+	require.Equal(t, 1, h.Count())
+	_, ok := <-sub.C()
+	require.False(t, ok)
+	<-sub.Done()
+
+	// publishing after closing must not panic on the closed queue
+	h.Publish(price("BTC", "USD"))
+	require.Len(t, other.C(), 1)
+}
+
+func TestSubscriptionContextTeardown(t *testing.T) {
+	h := New(nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := h.Subscribe(ctx, Filter{}, "")
+
+	cancel()
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("subscription wasn't closed with its context")
+	}
+	require.Equal(t, 0, h.Count())
+}
+
+func TestSubscriptionChurn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := make(chan *model.CurrentPrice)
+	h := New(source, make(chan error), nil)
+	go h.Run(ctx)
+
+	// keep publishing while subscriptions come and go
+	stop := make(chan struct{})
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				source <- price("BTC", "USD")
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5000; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			subCtx, subCancel := context.WithCancel(ctx)
+			sub := h.Subscribe(subCtx, Filter{}, "")
+			// torn down by their context, closed explicitly, or both at once
+			switch i % 3 {
+			case 0:
+				subCancel()
+				<-sub.Done()
+			case 1:
+				sub.Close()
+				subCancel()
+			default:
+				go sub.Close()
+				subCancel()
+				<-sub.Done()
+			}
+			for range sub.C() {
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	<-published
+
+	require.Equal(t, 0, h.Count())
+}
+
+func TestHubSlowSubscriberDoesNotBlock(t *testing.T) {
+	h := New(nil, nil, nil)
+	slow := h.Subscribe(context.Background(), Filter{}, "").C()
+	for i := 0; i < queueBufferSize+10; i++ {
+		h.Publish(price("BTC", "USD"))
+	}
+	require.Len(t, slow, queueBufferSize)
+}
+
+func TestHubSlowConsumerPolicies(t *testing.T) {
+	h := New(nil, nil, nil)
+	oldest := h.Subscribe(context.Background(), Filter{}, PolicyDropOldest)
+	newest := h.Subscribe(context.Background(), Filter{}, PolicyDropNewest)
+	conflated := h.Subscribe(context.Background(), Filter{}, PolicyConflate)
+	disconnected := h.Subscribe(context.Background(), Filter{}, PolicyDisconnect)
+
+	// fill the queues with BTC, then overflow them with one ETH price
+	for i := 0; i < queueBufferSize; i++ {
+		p := price("BTC", "USD")
+		p.Round = uint64(i + 1)
+		h.Publish(p)
+	}
+	h.Publish(price("ETH", "USD"))
+
+	require.Len(t, oldest.C(), queueBufferSize)
+	require.Equal(t, uint64(2), receive(t, oldest.C()).Round)
+	require.Equal(t, uint64(1), oldest.Stats().Dropped)
+
+	require.Len(t, newest.C(), queueBufferSize)
+	require.Equal(t, uint64(1), receive(t, newest.C()).Round)
+	require.Equal(t, uint64(1), newest.Stats().Dropped)
+
+	// only the latest BTC price is kept next to the ETH one
+	require.Len(t, conflated.C(), 2)
+	require.Equal(t, uint64(queueBufferSize), receive(t, conflated.C()).Round)
+	require.Equal(t, "ETH", receive(t, conflated.C()).Asset)
+	require.Equal(t, uint64(queueBufferSize-1), conflated.Stats().Dropped)
+
+	select {
+	case <-disconnected.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow consumer wasn't disconnected")
+	}
+	require.ErrorIs(t, disconnected.Err(), ErrSlowConsumer)
+	require.Nil(t, oldest.Err())
+
+	stats := h.Stats()
+	require.Len(t, stats, 3)
+	for _, s := range stats {
+		require.NotEqual(t, disconnected.ID(), s.ID)
+		require.Equal(t, queueBufferSize, s.QueueSize)
+	}
+}
+
+func TestHubDefaultPolicy(t *testing.T) {
+	h := New(nil, nil, nil).WithPolicy(PolicyConflate)
+	require.Equal(t, PolicyConflate, h.Subscribe(context.Background(), Filter{}, "").Policy())
+	require.Equal(t, PolicyDropNewest, h.Subscribe(context.Background(), Filter{}, PolicyDropNewest).Policy())
+
+	_, err := ParsePolicy("block")
+	require.Error(t, err)
+	policy, err := ParsePolicy(" Disconnect ")
+	require.NoError(t, err)
+	require.Equal(t, PolicyDisconnect, policy)
+}
+
+func TestHubReplay(t *testing.T) {
+	since := time.Unix(1706015736, 10)
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetSinceDate", mock.Anything, since, []string{"BTC"}).
+		Return([]*model.CurrentPrice{price("BTC", "USD"), price("BTC", "EUR")}, nil).Once()
+
+	h := New(nil, nil, repo).WithReplayTransform(func(p *model.CurrentPrice) *model.CurrentPrice {
+		res := *p
+		res.KeyID = "signed"
+		return &res
+	})
+	sub := h.Subscribe(context.Background(), Filter{Assets: []string{"BTC"}, Currencies: []string{"EUR"}}, "")
+	replayed, err := h.Replay(context.Background(), sub, since)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	require.Equal(t, "signed", replayed[0].KeyID)
+	_, ok := replayed[0].Rate("EUR")
+	require.True(t, ok)
+}
+
+func TestHubStoredCurrencies(t *testing.T) {
+	h := New(nil, nil, nil)
+	require.Nil(t, h.StoredCurrencies(nil))
+	require.Equal(t, []string{"EUR", "USD"}, h.StoredCurrencies([]string{"eur", "USD", "EUR"}))
+
+	// derived currencies are found in the prices quoting what they are derived from
+	h.WithCurrencySources(func(currency string) []string {
+		if currency == "jpy" {
+			return []string{"JPY", "USD"}
+		}
+		return []string{currency}
+	})
+	require.Equal(t, []string{"JPY", "USD", "EUR"}, h.StoredCurrencies([]string{"jpy", "USD", "EUR"}))
+}
+
+func TestHubResumeDeliversEveryUpdateOnce(t *testing.T) {
+	seqPrice := func(seq uint64) *model.CurrentPrice {
+		p := price("BTC", "USD")
+		p.Seq = seq
+		return p
+	}
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetSinceSeq", mock.Anything, uint64(3), []string{"BTC"}).
+		Return([]*model.CurrentPrice{seqPrice(4), seqPrice(5), seqPrice(6)}, nil).Once()
+	h := New(nil, nil, repo)
+	sub := h.Subscribe(context.Background(), Filter{Assets: []string{"BTC"}}, "")
+
+	// stored while the client was reconnecting, so published live and replayed
+	h.Publish(seqPrice(5))
+	h.Publish(seqPrice(6))
+
+	missed, err := h.Resume(context.Background(), sub, 3)
+	require.NoError(t, err)
+	require.Len(t, missed, 3)
+	for i, p := range missed {
+		require.Equal(t, uint64(4+i), p.Seq)
+	}
+	requireEmpty(t, sub.C())
+
+	// a late live copy of a replayed update is skipped too
+	h.Publish(seqPrice(4))
+	h.Publish(seqPrice(7))
+	require.Equal(t, uint64(7), receive(t, sub.C()).Seq)
+	requireEmpty(t, sub.C())
+}
+
+func TestHubReplayHandsOffToLive(t *testing.T) {
+	tick := func(seq uint64, at int64) *model.CurrentPrice {
+		p := price("BTC", "USD")
+		p.Seq = seq
+		p.Time.UpdatedISO = time.Unix(at, 0)
+		return p
+	}
+	since := time.Unix(1000, 0)
+	repo := mockRepo.NewMockPrices(t)
+	h := New(nil, nil, repo)
+	sub := h.Subscribe(context.Background(), Filter{}, PolicyDropNewest)
+
+	// published before the replay starts and again while it is queried, more than the queue holds
+	h.Publish(tick(3, 1003))
+	repo.On("GetSinceDate", mock.Anything, since, []string(nil)).
+		Run(func(mock.Arguments) {
+			h.Publish(tick(2, 1002))
+			for i := 0; i < queueBufferSize*2; i++ {
+				h.Publish(tick(uint64(10+i), int64(1010+i)))
+			}
+			// stored right before the query ran, it shows up late on the live path
+			h.Publish(tick(4, 1004))
+		}).
+		Return([]*model.CurrentPrice{tick(2, 1002), tick(1, 1001), tick(3, 1003), tick(4, 1004)}, nil).Once()
+
+	replayed, err := h.Replay(context.Background(), sub, since)
+	require.NoError(t, err)
+	require.Len(t, replayed, 4+queueBufferSize*2)
+	for i := 1; i < len(replayed); i++ {
+		require.True(t, replayed[i-1].Time.UpdatedISO.Before(replayed[i].Time.UpdatedISO), "out of order at %d", i)
+	}
+	require.Equal(t, uint64(0), sub.Stats().Dropped)
+	requireEmpty(t, sub.C())
+
+	// live updates continue right after the replay
+	h.Publish(tick(500, 2000))
+	require.Equal(t, uint64(500), receive(t, sub.C()).Seq)
+}
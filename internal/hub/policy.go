@@ -0,0 +1,112 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+// Policy decides what happens to a price published while a subscriber's queue is full.
+type Policy string
+
+const (
+	// PolicyDropOldest discards the oldest queued price to make room for the new one.
+	PolicyDropOldest Policy = "drop-oldest"
+	// PolicyDropNewest discards the new price.
+	PolicyDropNewest Policy = "drop-newest"
+	// PolicyConflate keeps only the latest queued price of every asset.
+	PolicyConflate Policy = "conflate"
+	// PolicyDisconnect closes the subscription with ErrSlowConsumer.
+	PolicyDisconnect Policy = "disconnect"
+)
+
+var ErrSlowConsumer = errors.New("slow consumer: queue is full")
+
+// ParsePolicy reads a policy name, an empty name means the hub default.
+func ParsePolicy(name string) (Policy, error) {
+	policy := Policy(strings.ToLower(strings.TrimSpace(name)))
+	switch policy {
+	case "", PolicyDropOldest, PolicyDropNewest, PolicyConflate, PolicyDisconnect:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown slow consumer policy %q", name)
+}
+
+// offer queues price following the subscription policy, it never blocks.
+func (s *Subscription) offer(price *model.CurrentPrice) {
+	s.offerMutex.Lock()
+	defer s.offerMutex.Unlock()
+	if s.closing || s.skipped(price) {
+		return
+	}
+	if s.buffering {
+		s.backlog = append(s.backlog, price)
+		return
+	}
+
+	select {
+	case s.queue <- price:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case PolicyDropNewest:
+		s.dropped.Add(1)
+	case PolicyDropOldest:
+		select {
+		case <-s.queue:
+			s.dropped.Add(1)
+		default:
+		}
+		s.tryQueue(price)
+	case PolicyConflate:
+		s.conflate(price)
+	case PolicyDisconnect:
+		s.dropped.Add(1)
+		s.closing = true
+		s.setErr(ErrSlowConsumer)
+		// the hub is locked while publishing, the subscription is removed once it is released
+		go s.Close()
+	}
+}
+
+// conflate replaces the queued prices with the latest one of every asset, in the order assets were queued.
+func (s *Subscription) conflate(price *model.CurrentPrice) {
+	var pending []*model.CurrentPrice
+drain:
+	for {
+		select {
+		case queued := <-s.queue:
+			pending = append(pending, queued)
+		default:
+			break drain
+		}
+	}
+	pending = append(pending, price)
+
+	index := make(map[string]int)
+	var latest []*model.CurrentPrice
+	for _, p := range pending {
+		if i, ok := index[p.AssetSymbol()]; ok {
+			latest[i] = p
+			continue
+		}
+		index[p.AssetSymbol()] = len(latest)
+		latest = append(latest, p)
+	}
+	s.dropped.Add(uint64(len(pending) - len(latest)))
+	for _, p := range latest {
+		s.tryQueue(p)
+	}
+}
+
+func (s *Subscription) tryQueue(price *model.CurrentPrice) {
+	select {
+	case s.queue <- price:
+	default:
+		s.dropped.Add(1)
+	}
+}
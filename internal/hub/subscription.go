@@ -0,0 +1,186 @@
+package hub
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/google/uuid"
+)
+
+// Subscription is the registration of one subscriber, it keeps the same ID for its whole life.
+type Subscription struct {
+	id     string
+	filter Filter
+	policy Policy
+	hub    *Hub
+	queue  chan *model.CurrentPrice
+
+	offerMutex sync.Mutex
+	closing    bool
+	dropped    atomic.Uint64
+	// backlog holds the prices published while a replay is queried, instead of the bounded queue
+	buffering bool
+	backlog   []*model.CurrentPrice
+	// replayed holds the sequence numbers the subscriber got through a replay, skipped when published
+	replayed map[uint64]struct{}
+
+	errMutex sync.Mutex
+	err      error
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// SubscriptionStats describes the queue of a subscriber.
+type SubscriptionStats struct {
+	ID         string   `json:"id"`
+	Policy     Policy   `json:"policy"`
+	Assets     []string `json:"assets,omitempty"`
+	Currencies []string `json:"currencies,omitempty"`
+	QueueDepth int      `json:"queue_depth"`
+	QueueSize  int      `json:"queue_size"`
+	Dropped    uint64   `json:"dropped"`
+}
+
+func newSubscription(h *Hub, filter Filter, policy Policy) *Subscription {
+	return &Subscription{
+		id:     uuid.NewString(),
+		filter: filter,
+		policy: policy,
+		hub:    h,
+		queue:  make(chan *model.CurrentPrice, queueBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+func (s *Subscription) Filter() Filter {
+	s.hub.mutex.RLock()
+	defer s.hub.mutex.RUnlock()
+	return s.filter
+}
+
+// SetFilter changes what the subscription receives from the next published price on.
+func (s *Subscription) SetFilter(filter Filter) {
+	s.hub.mutex.Lock()
+	defer s.hub.mutex.Unlock()
+	s.filter = filter
+}
+
+func (s *Subscription) Policy() Policy {
+	return s.policy
+}
+
+// C delivers matching prices, it is closed once the subscription is.
+func (s *Subscription) C() <-chan *model.CurrentPrice {
+	return s.queue
+}
+
+// Done is closed once the subscription is.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the hub closed the subscription, nil when it was closed by its owner.
+func (s *Subscription) Err() error {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	return s.err
+}
+
+func (s *Subscription) setErr(err error) {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	s.err = err
+}
+
+func (s *Subscription) Stats() SubscriptionStats {
+	s.hub.mutex.RLock()
+	defer s.hub.mutex.RUnlock()
+	return s.stats()
+}
+
+// stats reads the subscription, the caller holds the hub lock.
+func (s *Subscription) stats() SubscriptionStats {
+	return SubscriptionStats{
+		ID:         s.id,
+		Policy:     s.policy,
+		Assets:     s.filter.Assets,
+		Currencies: s.filter.Currencies,
+		QueueDepth: len(s.queue),
+		QueueSize:  cap(s.queue),
+		Dropped:    s.dropped.Load(),
+	}
+}
+
+// buffer holds published prices back until takeOver, so none is dropped while a replay is queried.
+func (s *Subscription) buffer() {
+	s.offerMutex.Lock()
+	defer s.offerMutex.Unlock()
+	s.buffering = true
+}
+
+// takeOver empties the queue and the backlog into replayed, dropping what was replayed already, and makes the
+// subscription skip replayed prices published later. The caller holds the hub lock.
+func (s *Subscription) takeOver(replayed []*model.CurrentPrice) []*model.CurrentPrice {
+	s.offerMutex.Lock()
+	defer s.offerMutex.Unlock()
+	if s.closing {
+		s.buffering, s.backlog = false, nil
+		return replayed
+	}
+
+	s.replayed = make(map[uint64]struct{}, len(replayed))
+	for _, price := range replayed {
+		if price.Seq != 0 {
+			s.replayed[price.Seq] = struct{}{}
+		}
+	}
+
+	res := replayed
+	// the queue holds what was published before buffering started
+drain:
+	for {
+		select {
+		case queued := <-s.queue:
+			if !s.skipped(queued) {
+				res = append(res, queued)
+			}
+		default:
+			break drain
+		}
+	}
+	for _, price := range s.backlog {
+		if !s.skipped(price) {
+			res = append(res, price)
+		}
+	}
+	s.buffering, s.backlog = false, nil
+	return res
+}
+
+// skipped reports whether price was replayed already, the caller holds offerMutex. Prices are
+// told apart by sequence number: a price without one was never stored, so it can't be replayed.
+func (s *Subscription) skipped(price *model.CurrentPrice) bool {
+	if price.Seq == 0 {
+		return false
+	}
+	if _, ok := s.replayed[price.Seq]; ok {
+		// every price is published once, so it won't be needed again
+		delete(s.replayed, price.Seq)
+		return true
+	}
+	return false
+}
+
+// Close removes the subscription from the hub, it is safe to call several times and concurrently.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.remove(s)
+		close(s.done)
+	})
+}
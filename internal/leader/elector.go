@@ -0,0 +1,166 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// Status is the leadership as last seen by an instance.
+type Status struct {
+	ID          string     `json:"id"`
+	Leader      bool       `json:"leader"`
+	LeaderID    string     `json:"leader_id,omitempty"`
+	LeaderSince *time.Time `json:"leader_since,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Elector campaigns for a lease and runs the work reserved to its holder while it holds it.
+// The lease is renewed every third of its TTL, so followers take over at most TTL after the leader died.
+// A leader failing to renew steps down a third of the TTL before its lease expires, so clocks of instances
+// may disagree by that much before two of them lead at once.
+type Elector struct {
+	leases repository.Leases
+	name   string
+	id     string
+	ttl    time.Duration
+	now    func() time.Time
+
+	mutex     sync.RWMutex
+	lease     *model.Lease
+	leading   bool
+	renewedAt time.Time
+}
+
+func NewElector(leases repository.Leases, name, id string, ttl time.Duration) *Elector {
+	return &Elector{
+		leases: leases,
+		name:   name,
+		id:     id,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Run campaigns until ctx is done. lead runs while this instance holds the lease, its context is
+// cancelled as soon as the lease is lost and Run waits for it to return before campaigning again.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	// stop cancels the running lead and waits for it to return, nil while following
+	var stop func()
+	stepDown := func() {
+		if stop != nil {
+			stop()
+			stop = nil
+		}
+	}
+	defer func() {
+		stepDown()
+		if e.IsLeader() {
+			// let a follower take over right away
+			ctx, cancel := context.WithTimeout(context.Background(), e.ttl)
+			defer cancel()
+			if err := e.leases.Release(ctx, e.name, e.id); err != nil {
+				log.Err(err).Msgf("failed to release %s lease", e.name)
+			}
+		}
+		e.mutex.Lock()
+		e.leading = false
+		e.mutex.Unlock()
+	}()
+
+	for {
+		leading := e.campaign(ctx)
+		switch {
+		case leading && stop == nil:
+			log.Info().Msgf("%s is the %s leader", e.id, e.name)
+			stop = e.lead(ctx, lead)
+		case !leading && stop != nil:
+			log.Warn().Msgf("%s lost the %s lease", e.id, e.name)
+			stepDown()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead starts lead in the background and returns how to stop it.
+func (e *Elector) lead(ctx context.Context, lead func(ctx context.Context)) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		lead(ctx)
+	}()
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+// campaign tries to acquire or renew the lease and reports whether this instance holds it.
+func (e *Elector) campaign(ctx context.Context) bool {
+	// the lease is granted at some point of the request, it is counted from before it
+	start := e.now()
+	e.mutex.RLock()
+	timeout := e.ttl / 3
+	if e.leading {
+		// a leader doesn't wait for the renewal past the time it has to step down
+		timeout = min(timeout, e.heldUntil().Sub(start))
+	}
+	e.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	lease, err := e.leases.Acquire(ctx, e.name, e.id, e.ttl)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if err != nil {
+		log.Err(err).Msgf("failed to acquire %s lease", e.name)
+		e.leading = e.leading && e.now().Before(e.heldUntil())
+		return e.leading
+	}
+
+	e.lease = lease
+	e.leading = lease.Holder == e.id
+	if e.leading {
+		e.renewedAt = start
+	}
+	return e.leading
+}
+
+// heldUntil is when a leader which couldn't renew steps down, a third of the TTL before its lease expires.
+func (e *Elector) heldUntil() time.Time {
+	return e.renewedAt.Add(e.ttl - e.ttl/3)
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.leading
+}
+
+func (e *Elector) Status() Status {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	status := Status{ID: e.id, Leader: e.leading}
+	if e.lease != nil && e.lease.ExpiresAt.After(e.now()) {
+		since, expires := e.lease.AcquiredAt, e.lease.ExpiresAt
+		status.LeaderID = e.lease.Holder
+		status.LeaderSince = &since
+		status.ExpiresAt = &expires
+	}
+	return status
+}
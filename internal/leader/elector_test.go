@@ -0,0 +1,166 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseStore keeps leases in memory like the leases collection, holders listed in down can't reach it and
+// the requests of those listed in hung never complete.
+type leaseStore struct {
+	mutex  sync.Mutex
+	leases map[string]model.Lease
+	down   map[string]bool
+	hung   map[string]bool
+}
+
+func newLeaseStore() *leaseStore {
+	return &leaseStore{leases: make(map[string]model.Lease), down: make(map[string]bool), hung: make(map[string]bool)}
+}
+
+func (s *leaseStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*model.Lease, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.down[holder] {
+		return nil, errors.New("connection refused")
+	}
+	if s.hung[holder] {
+		s.mutex.Unlock()
+		<-ctx.Done()
+		s.mutex.Lock()
+		return nil, ctx.Err()
+	}
+
+	now := time.Now()
+	lease, ok := s.leases[name]
+	switch {
+	case ok && lease.Holder == holder:
+		lease.ExpiresAt = now.Add(ttl)
+	case !ok || !lease.ExpiresAt.After(now):
+		lease = model.Lease{Name: name, Holder: holder, AcquiredAt: now, ExpiresAt: now.Add(ttl)}
+	}
+	s.leases[name] = lease
+	return &lease, nil
+}
+
+func (s *leaseStore) Release(_ context.Context, name, holder string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.leases[name].Holder == holder {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+func (s *leaseStore) disconnect(holder string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.down[holder] = true
+}
+
+// hang makes the requests of holder block and returns when the lease named name expires.
+func (s *leaseStore) hang(holder, name string) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hung[holder] = true
+	return s.leases[name].ExpiresAt
+}
+
+// leading counts the instances running their lead.
+type leading struct {
+	count atomic.Int32
+}
+
+func (l *leading) lead(ctx context.Context) {
+	l.count.Add(1)
+	defer l.count.Add(-1)
+	<-ctx.Done()
+}
+
+func TestFollowerTakesOverDeadLeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newLeaseStore()
+	ttl := 150 * time.Millisecond
+	var leaders leading
+
+	first := NewElector(store, "fetcher", "first", ttl)
+	go first.Run(ctx, leaders.lead)
+	require.Eventually(t, first.IsLeader, time.Second, 5*time.Millisecond)
+
+	second := NewElector(store, "fetcher", "second", ttl)
+	go second.Run(ctx, leaders.lead)
+	require.Eventually(t, func() bool { return second.Status().LeaderID == "first" }, time.Second, 5*time.Millisecond)
+	require.False(t, second.IsLeader())
+	require.Equal(t, int32(1), leaders.count.Load())
+
+	// the leader can't renew anymore, it steps down and the follower takes over once the lease expired
+	store.disconnect("first")
+	require.Eventually(t, second.IsLeader, 3*ttl, 5*time.Millisecond)
+	require.False(t, first.IsLeader())
+	// the lead of the new leader starts right after it won
+	require.Eventually(t, func() bool { return leaders.count.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	status := second.Status()
+	require.Equal(t, "second", status.ID)
+	require.Equal(t, "second", status.LeaderID)
+	require.NotNil(t, status.LeaderSince)
+}
+
+func TestLeaderStepsDownBeforeLeaseExpires(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newLeaseStore()
+	ttl := 150 * time.Millisecond
+	stopped := make(chan time.Time, 1)
+
+	elector := NewElector(store, "fetcher", "first", ttl)
+	go elector.Run(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+		stopped <- time.Now()
+	})
+	require.Eventually(t, elector.IsLeader, time.Second, 5*time.Millisecond)
+
+	// renewals never complete, the leader stops before another instance may take the lease over
+	expires := store.hang("first", "fetcher")
+	select {
+	case at := <-stopped:
+		require.True(t, at.Before(expires), "stepped down %s after the lease expired", at.Sub(expires))
+	case <-time.After(3 * ttl):
+		t.Fatal("leader didn't step down")
+	}
+	require.False(t, elector.IsLeader())
+}
+
+func TestLeaderReleasesOnShutdown(t *testing.T) {
+	leases := mockRepo.NewMockLeases(t)
+	lease := &model.Lease{Name: "fetcher", Holder: "first", ExpiresAt: time.Now().Add(time.Minute)}
+	leases.On("Acquire", mock.Anything, "fetcher", "first", time.Minute).Return(lease, nil).Once()
+	leases.On("Release", mock.Anything, "fetcher", "first").Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	elector := NewElector(leases, "fetcher", "first", time.Minute)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		elector.Run(ctx, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		})
+	}()
+
+	<-started
+	cancel()
+	<-done
+	require.False(t, elector.IsLeader())
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Candle aggregates the ticks of an asset quoted in one currency over the interval starting at Start.
+type Candle struct {
+	Asset    string    `bson:"asset" json:"asset"`
+	Currency string    `bson:"currency" json:"currency"`
+	Interval string    `bson:"interval" json:"interval"`
+	Start    time.Time `bson:"start" json:"start"`
+	Open     Decimal   `bson:"open" json:"open"`
+	High     Decimal   `bson:"high" json:"high"`
+	Low      Decimal   `bson:"low" json:"low"`
+	Close    Decimal   `bson:"close" json:"close"`
+	// FirstTick and LastTick are the times of the ticks Open and Close come from, a late tick replaces
+	// them when it is earlier or later.
+	FirstTick time.Time `bson:"first_tick" json:"-"`
+	LastTick  time.Time `bson:"last_tick" json:"-"`
+	// Closed is set once the interval is over, late ticks may still correct the candle afterwards.
+	Closed bool `bson:"-" json:"closed"`
+}
+
+// Add merges a tick of value at time at into the candle.
+func (c *Candle) Add(at time.Time, value Decimal) {
+	c.Merge(Candle{Open: value, High: value, Low: value, Close: value, FirstTick: at, LastTick: at})
+}
+
+// Merge folds the ticks of other, a candle of the same interval, into the candle. The result doesn't depend
+// on the order candles are merged in, nor on merging one twice.
+func (c *Candle) Merge(other Candle) {
+	if c.FirstTick.IsZero() {
+		c.Open, c.High, c.Low, c.Close = other.Open, other.High, other.Low, other.Close
+		c.FirstTick, c.LastTick = other.FirstTick, other.LastTick
+		return
+	}
+	if other.FirstTick.Before(c.FirstTick) {
+		c.Open, c.FirstTick = other.Open, other.FirstTick
+	}
+	if !other.LastTick.Before(c.LastTick) {
+		c.Close, c.LastTick = other.Close, other.LastTick
+	}
+	if other.High.Cmp(c.High) > 0 {
+		c.High = other.High
+	}
+	if other.Low.Cmp(c.Low) < 0 {
+		c.Low = other.Low
+	}
+}
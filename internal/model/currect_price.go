@@ -1,28 +1,88 @@
 package model
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultAsset is the asset of prices stored before assets were introduced.
+const DefaultAsset = "BTC"
 
 type CurrentPrice struct {
+	Asset      string           `json:"asset,omitempty"`
 	Time       CurrentPriceTime `json:"time"`
 	Disclaimer string           `json:"disclaimer"`
 	ChartName  string           `json:"chartName"`
-	Bpi        CurrentPriceBpi  `json:"bpi"`
+	// Bpi maps an upper-cased ISO-4217 quote currency onto its rate.
+	Bpi     map[string]CurrentPriceRate `json:"bpi"`
+	Sources []string                    `json:"sources,omitempty"`
+	// Round increases with every update published under the deviation/heartbeat policy.
+	Round uint64 `json:"round,omitempty"`
+	// Seq is assigned when the price is stored and increases across all assets, clients resume from it.
+	Seq uint64 `json:"seq,omitempty"`
+	// Signatures maps quote currencies onto the attestation of their rate, signed by KeyID.
+	Signatures map[string]string `json:"signatures,omitempty"`
+	KeyID      string            `json:"key_id,omitempty"`
 }
 
 type CurrentPriceTime struct {
 	UpdatedISO time.Time `json:"updatedISO"`
 }
 
-type CurrentPriceBpi struct {
-	Usd CurrentPriceRate `json:"USD"`
-	Gbp CurrentPriceRate `json:"GBP"`
-	Eur CurrentPriceRate `json:"EUR"`
+type CurrentPriceRate struct {
+	Code        string `json:"code"`
+	Symbol      string `json:"symbol"`
+	Rate        string `json:"rate"`
+	Description string `json:"description"`
+	// Value is the exact rate, read from CoinDesk's rate_float number literal.
+	Value Decimal `json:"rate_float"`
+	// Derived is set when the rate was computed from FX reference rates instead of being quoted.
+	Derived bool `json:"derived,omitempty"`
 }
 
-type CurrentPriceRate struct {
-	Code        string  `json:"code"`
-	Symbol      string  `json:"symbol"`
-	Rate        string  `json:"rate"`
-	Description string  `json:"description"`
-	RateFloat   float64 `json:"rate_float"`
+// AssetSymbol returns the upper-cased asset, DefaultAsset when it is not set.
+func (p *CurrentPrice) AssetSymbol() string {
+	if p.Asset == "" {
+		return DefaultAsset
+	}
+	return strings.ToUpper(p.Asset)
+}
+
+// MatchesAssets reports whether the price belongs to one of assets, an empty list matches everything.
+func (p *CurrentPrice) MatchesAssets(assets []string) bool {
+	if len(assets) == 0 {
+		return true
+	}
+	symbol := p.AssetSymbol()
+	for _, asset := range assets {
+		if strings.EqualFold(asset, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate returns the rate quoted in the given currency code.
+func (p *CurrentPrice) Rate(code string) (CurrentPriceRate, bool) {
+	rate, ok := p.Bpi[strings.ToUpper(code)]
+	return rate, ok
+}
+
+// SetRate stores rate under the upper-cased currency code.
+func (p *CurrentPrice) SetRate(code string, rate CurrentPriceRate) {
+	if p.Bpi == nil {
+		p.Bpi = make(map[string]CurrentPriceRate)
+	}
+	p.Bpi[strings.ToUpper(code)] = rate
+}
+
+// Currencies returns quoted currency codes in alphabetical order.
+func (p *CurrentPrice) Currencies() []string {
+	codes := make([]string, 0, len(p.Bpi))
+	for code := range p.Bpi {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
 }
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// iso4217 lists active ISO-4217 currency codes accepted as quote currencies.
+var iso4217 = toSet(strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP BYN BZD
+	CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD
+	GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT
+	LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+	NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP
+	STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VED VES VND VUV WST XAF XCD
+	XOF XPF YER ZAR ZMW ZWL
+`))
+
+// IsKnownCurrency reports whether code is an ISO-4217 currency code, case-insensitively.
+func IsKnownCurrency(code string) bool {
+	_, ok := iso4217[strings.ToUpper(code)]
+	return ok
+}
+
+// UnknownCurrencies returns codes which are not ISO-4217 currencies.
+func UnknownCurrencies(codes []string) []string {
+	var unknown []string
+	for _, code := range codes {
+		if !IsKnownCurrency(code) {
+			unknown = append(unknown, code)
+		}
+	}
+	return unknown
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+// DecimalSpec declares how rates quoted in a currency are kept: Precision significant digits
+// at most, rounded to Scale digits after the decimal point.
+type DecimalSpec struct {
+	Precision int
+	Scale     int32
+}
+
+// DefaultDecimalSpec fits Decimal128 and keeps sub-cent prices of low-priced assets.
+var DefaultDecimalSpec = DecimalSpec{Precision: maxDecimalDigits, Scale: 8}
+
+var (
+	decimalSpecsMutex sync.RWMutex
+	decimalSpecs      = map[string]DecimalSpec{}
+)
+
+// SetDecimalSpec overrides DefaultDecimalSpec for code.
+func SetDecimalSpec(code string, spec DecimalSpec) error {
+	code = strings.ToUpper(code)
+	if !IsKnownCurrency(code) {
+		return fmt.Errorf("unknown currency %s", code)
+	}
+	if spec.Precision < 1 || spec.Precision > maxDecimalDigits || spec.Scale < 0 || int(spec.Scale) > spec.Precision {
+		return fmt.Errorf("wrong precision %d and scale %d for %s", spec.Precision, spec.Scale, code)
+	}
+
+	decimalSpecsMutex.Lock()
+	defer decimalSpecsMutex.Unlock()
+	decimalSpecs[code] = spec
+	return nil
+}
+
+// DecimalSpecFor returns the precision and scale declared for code.
+func DecimalSpecFor(code string) DecimalSpec {
+	decimalSpecsMutex.RLock()
+	defer decimalSpecsMutex.RUnlock()
+	if spec, ok := decimalSpecs[strings.ToUpper(code)]; ok {
+		return spec
+	}
+	return DefaultDecimalSpec
+}
+
+// RoundRate rounds value to the scale declared for code.
+func RoundRate(code string, value Decimal) Decimal {
+	return value.Round(DecimalSpecFor(code).Scale)
+}
@@ -0,0 +1,302 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// maxDecimalDigits is the number of significant digits a Decimal128 can hold.
+const maxDecimalDigits = 34
+
+// maxExponent bounds the exponent ParseDecimal accepts, larger ones would allocate huge coefficients.
+const maxExponent = 1000
+
+var bigTen = big.NewInt(10)
+
+// Decimal is an exact fixed-point number, coef * 10^-scale. The zero value is 0.
+// Decimals are immutable, every operation returns a new value.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+// NewDecimal returns coef * 10^-scale.
+func NewDecimal(coef int64, scale int32) Decimal {
+	return normalized(big.NewInt(coef), scale)
+}
+
+// ParseDecimal reads plain or exponent notation, e.g. "41234.5678", "-0.01" or "1.5E+3".
+// Thousands separators are accepted since some providers format rates with them.
+func ParseDecimal(s string) (Decimal, error) {
+	raw := strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	mantissa, exponent := raw, int64(0)
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(raw[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("wrong decimal %q", s)
+		}
+		if exp > maxExponent || exp < -maxExponent {
+			return Decimal{}, fmt.Errorf("exponent of decimal %q is out of range", s)
+		}
+		mantissa, exponent = raw[:i], exp
+	}
+
+	scale := int64(0)
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = int64(len(mantissa) - i - 1)
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	digits := strings.TrimLeft(mantissa, "+-")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" || len(mantissa)-len(digits) > 1 {
+		return Decimal{}, fmt.Errorf("wrong decimal %q", s)
+	}
+
+	coef, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("wrong decimal %q", s)
+	}
+	scale -= exponent
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return normalized(coef, int32(scale)), nil
+}
+
+// MustParseDecimal is ParseDecimal panicking on malformed input, for constants and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromFloat converts f using its shortest exact representation, 0.1 becomes 0.1.
+func DecimalFromFloat(f float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func (d Decimal) bigCoef() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or 1.
+func (d Decimal) Sign() int {
+	return d.bigCoef().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Digits returns the number of significant digits.
+func (d Decimal) Digits() int {
+	if d.IsZero() {
+		return 1
+	}
+	return len(new(big.Int).Abs(d.coef).String())
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return normalized(new(big.Int).Add(a, b), maxScale(d, o))
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return normalized(new(big.Int).Sub(a, b), maxScale(d, o))
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return normalized(new(big.Int).Mul(d.bigCoef(), o.bigCoef()), d.scale+o.scale)
+}
+
+// Quo divides d by o keeping scale digits after the decimal point, rounding half away from zero.
+// Dividing by zero returns zero, callers check the divisor when it matters.
+func (d Decimal) Quo(o Decimal, scale int32) Decimal {
+	if o.IsZero() {
+		return Decimal{}
+	}
+	// d/o * 10^scale = d.coef * 10^(scale + o.scale - d.scale) / o.coef
+	num := new(big.Int).Set(d.bigCoef())
+	den := new(big.Int).Set(o.coef)
+	if shift := scale + o.scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return normalized(divRound(num, den), scale)
+}
+
+// Round returns d with at most scale digits after the decimal point, rounding half away from zero.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+	return normalized(divRound(d.bigCoef(), pow10(d.scale-scale)), scale)
+}
+
+// Float64 returns the nearest float, only meant for tolerances and statistics.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d in plain notation without trailing zeros.
+func (d Decimal) String() string {
+	coef := d.bigCoef()
+	if d.scale == 0 {
+		return coef.String()
+	}
+
+	digits := new(big.Int).Abs(coef).String()
+	if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	res := digits[:point] + "." + digits[point:]
+	if coef.Sign() < 0 {
+		res = "-" + res
+	}
+	return res
+}
+
+// MarshalJSON writes d as a JSON number literal so no precision is lost on the wire.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads both number literals and quoted numbers.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if raw == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalBSONValue stores d as Decimal128.
+func (d Decimal) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if d.Digits() > maxDecimalDigits {
+		return 0, nil, fmt.Errorf("decimal %s doesn't fit into Decimal128", d)
+	}
+	dec, err := primitive.ParseDecimal128(d.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bsontype.Decimal128, bsoncore.AppendDecimal128(nil, dec), nil
+}
+
+// UnmarshalBSONValue reads Decimal128 as well as doubles and integers written by older versions.
+func (d *Decimal) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+	var err error
+	switch t {
+	case bsontype.Decimal128:
+		*d, err = ParseDecimal(value.Decimal128().String())
+	case bsontype.Double:
+		*d, err = DecimalFromFloat(value.Double())
+	case bsontype.Int32:
+		*d = NewDecimal(int64(value.Int32()), 0)
+	case bsontype.Int64:
+		*d = NewDecimal(value.Int64(), 0)
+	case bsontype.String:
+		*d, err = ParseDecimal(value.StringValue())
+	case bsontype.Null, bsontype.Undefined:
+		*d = Decimal{}
+	default:
+		err = fmt.Errorf("cannot decode %s into a decimal", t)
+	}
+	return err
+}
+
+// normalized drops trailing zeros so equal values share one representation.
+func normalized(coef *big.Int, scale int32) Decimal {
+	if coef.Sign() == 0 {
+		return Decimal{}
+	}
+	rem := new(big.Int)
+	for scale > 0 {
+		q, r := new(big.Int).QuoRem(coef, bigTen, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		coef, scale = q, scale-1
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	scale := maxScale(a, b)
+	return rescaled(a, scale), rescaled(b, scale)
+}
+
+func rescaled(d Decimal, scale int32) *big.Int {
+	if scale == d.scale {
+		return d.bigCoef()
+	}
+	return new(big.Int).Mul(d.bigCoef(), pow10(scale-d.scale))
+}
+
+func maxScale(a, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// divRound divides num by den rounding half away from zero.
+func divRound(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// |2r| >= |den| means the remainder is at least half way
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
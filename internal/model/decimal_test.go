@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for in, want := range map[string]string{
+		"41234.5678":   "41234.5678",
+		"41,234.5678":  "41234.5678",
+		"-0.010":       "-0.01",
+		"1.5E+3":       "1500",
+		"12345e-7":     "0.0012345",
+		"0.1":          "0.1",
+		"100.00000000": "100",
+		"1e1000":       "1" + strings.Repeat("0", 1000),
+	} {
+		d, err := ParseDecimal(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, d.String(), in)
+	}
+
+	for _, in := range []string{"", "abc", "1.2.3", "--1", "1e", "NaN", "1e1001", "1e-1001", "1e999999999"} {
+		_, err := ParseDecimal(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	require.Equal(t, "0.3", a.Add(b).String())
+	require.True(t, a.Add(b).Equal(MustParseDecimal("0.30")))
+	require.Equal(t, "-0.1", a.Sub(b).String())
+	require.Equal(t, "0.02", a.Mul(b).String())
+	require.Equal(t, "0.3333", NewDecimal(1, 0).Quo(NewDecimal(3, 0), 4).String())
+	require.Equal(t, "0.6667", NewDecimal(2, 0).Quo(NewDecimal(3, 0), 4).String())
+	require.Equal(t, "-2.35", MustParseDecimal("-2.345").Round(2).String())
+	require.Equal(t, -1, a.Cmp(b))
+	require.True(t, Decimal{}.IsZero())
+	require.Equal(t, "0", Decimal{}.String())
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var rate CurrentPriceRate
+	require.NoError(t, json.Unmarshal([]byte(`{"rate_float": 41234.123456789012345}`), &rate))
+	require.Equal(t, "41234.123456789012345", rate.Value.String())
+
+	out, err := json.Marshal(struct{ V Decimal }{rate.Value})
+	require.NoError(t, err)
+	require.Equal(t, `{"V":41234.123456789012345}`, string(out))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"rate_float": "0.5"}`), &rate))
+	require.Equal(t, "0.5", rate.Value.String())
+}
+
+func TestDecimalBSON(t *testing.T) {
+	in := PricesCurrentPriceRate{Code: "USD", Value: MustParseDecimal("41234.123456789012345")}
+	data, err := bson.Marshal(in)
+	require.NoError(t, err)
+
+	raw := bson.Raw(data).Lookup("rate_float")
+	require.Equal(t, bson.TypeDecimal128, raw.Type)
+
+	var out PricesCurrentPriceRate
+	require.NoError(t, bson.Unmarshal(data, &out))
+	require.Equal(t, in.Value.String(), out.Value.String())
+
+	// documents written before decimals hold doubles
+	legacy, err := bson.Marshal(bson.M{"code": "USD", "rate_float": 41234.5678})
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(legacy, &out))
+	require.Equal(t, "41234.5678", out.Value.String())
+
+	_, err = bson.Marshal(PricesCurrentPriceRate{Value: MustParseDecimal("1234567890123456789012345678901234.5")})
+	require.Error(t, err)
+}
+
+func TestDecimalSpec(t *testing.T) {
+	require.Equal(t, DefaultDecimalSpec, DecimalSpecFor("usd"))
+	require.Error(t, SetDecimalSpec("XYZ", DecimalSpec{Precision: 10, Scale: 2}))
+	require.Error(t, SetDecimalSpec("JPY", DecimalSpec{Precision: 2, Scale: 4}))
+
+	require.NoError(t, SetDecimalSpec("jpy", DecimalSpec{Precision: 20, Scale: 2}))
+	defer func() {
+		decimalSpecsMutex.Lock()
+		delete(decimalSpecs, "JPY")
+		decimalSpecsMutex.Unlock()
+	}()
+	require.Equal(t, "6123456.79", RoundRate("JPY", MustParseDecimal("6123456.789")).String())
+}
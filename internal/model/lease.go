@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Lease is held by the instance allowed to do some work until it expires, the holder keeps renewing it.
+type Lease struct {
+	Name       string    `bson:"_id"`
+	Holder     string    `bson:"holder"`
+	AcquiredAt time.Time `bson:"acquired_at"`
+	ExpiresAt  time.Time `bson:"expires_at"`
+}
@@ -1,26 +1,49 @@
 package model
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type Prices struct {
-	CreatedAt int64      `bson:"created_at"`
-	Price     PricesInfo `bson:"price"`
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Asset     string             `bson:"asset,omitempty"`
+	CreatedAt int64              `bson:"created_at"`
+	Price     PricesInfo         `bson:"price"`
+	Round     uint64             `bson:"round,omitempty"`
+	// Seq orders every stored price across assets, prices stored by older versions have none.
+	Seq uint64 `bson:"seq,omitempty"`
+	// Batch is the ID of the PriceBatch committing to this price, 0 until it is sealed.
+	Batch int64 `bson:"batch,omitempty"`
 }
 
 type PricesInfo struct {
-	Disclaimer string                `bson:"disclaimer"`
-	ChartName  string                `bson:"chartName"`
-	Bpi        PricesCurrentPriceBpi `bson:"bpi"`
+	Disclaimer string                            `bson:"disclaimer"`
+	ChartName  string                            `bson:"chartName"`
+	Bpi        map[string]PricesCurrentPriceRate `bson:"bpi"`
+	Sources    []string                          `bson:"sources,omitempty"`
 }
 
-type PricesCurrentPriceBpi struct {
-	Usd PricesCurrentPriceRate `bson:"USD"`
-	Gbp PricesCurrentPriceRate `bson:"GBP"`
-	Eur PricesCurrentPriceRate `bson:"EUR"`
+type PricesCurrentPriceRate struct {
+	Code        string `bson:"code"`
+	Symbol      string `bson:"symbol"`
+	Rate        string `bson:"rate"`
+	Description string `bson:"description"`
+	// Value is stored as Decimal128, prices saved by older versions hold a double.
+	Value Decimal `bson:"rate_float"`
 }
 
-type PricesCurrentPriceRate struct {
-	Code        string  `bson:"code"`
-	Symbol      string  `bson:"symbol"`
-	Rate        string  `bson:"rate"`
-	Description string  `bson:"description"`
-	RateFloat   float64 `bson:"rate_float"`
+// QuarantinedPrice is a tick rejected by validation, kept for inspection.
+type QuarantinedPrice struct {
+	Prices     `bson:",inline"`
+	Reason     string `bson:"reason"`
+	RejectedAt int64  `bson:"rejected_at"`
+}
+
+// PriceBatch commits to a sequence of stored prices with the Merkle root of their leaves.
+type PriceBatch struct {
+	ID int64 `bson:"_id"`
+	// Root is the hex encoded Merkle root.
+	Root     string               `bson:"root"`
+	PriceIDs []primitive.ObjectID `bson:"price_ids"`
+	From     int64                `bson:"from"`
+	To       int64                `bson:"to"`
+	SealedAt int64                `bson:"sealed_at"`
 }
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const batchesCollection = "price_batches"
+
+// claimTimeout is how long a batch ID stays claimed before its sealer is considered gone.
+const claimTimeout = time.Minute
+
+var ErrNotFound = errors.New("not found")
+
+//go:generate mockery --name=Batches --structname=MockBatches --outpkg=repository --output ./mocks --filename batches_mock.go
+type Batches interface {
+	// Unsealed returns up to limit prices not committed to by any batch yet, oldest first.
+	Unsealed(ctx context.Context, limit int) ([]model.Prices, error)
+	// Seal stores batch under the next batch ID and marks its prices as sealed, it fails when any of them is sealed.
+	Seal(ctx context.Context, batch *model.PriceBatch) error
+	Get(ctx context.Context, id int64) (*model.PriceBatch, error)
+	// FindPrice returns the price stored under seq.
+	FindPrice(ctx context.Context, seq uint64) (*model.Prices, error)
+	// Prices returns the prices of batch in the order they were committed.
+	Prices(ctx context.Context, batch *model.PriceBatch) ([]model.Prices, error)
+}
+
+type batches struct {
+	pool *mongo.Database
+}
+
+func NewBatches(conn *mongo.Database) *batches {
+	return &batches{
+		pool: conn,
+	}
+}
+
+func (a *batches) Unsealed(ctx context.Context, limit int) ([]model.Prices, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+	cursor, err := a.pool.Collection(collection).Find(ctx, bson.M{"batch": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []model.Prices
+	if err = cursor.All(ctx, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Seal claims the next batch ID with a placeholder before marking any price, so of two sealers racing for an ID
+// the loser fails without touching prices. Only unsealed prices are marked, and the placeholder is replaced by batch
+// once all of them were. Claims outliving claimTimeout were left by a failed sealer, their prices are released.
+func (a *batches) Seal(ctx context.Context, batch *model.PriceBatch) error {
+	if err := a.releaseStale(ctx); err != nil {
+		return err
+	}
+
+	var last model.PriceBatch
+	opts := options.FindOne().SetSort(bson.M{"_id": -1}).SetProjection(bson.M{"_id": 1})
+	err := a.pool.Collection(batchesCollection).FindOne(ctx, bson.M{}, opts).Decode(&last)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	batch.ID = last.ID + 1
+	claim := bson.M{"_id": batch.ID, "claimed_at": time.Now().UTC()}
+	if _, err = a.pool.Collection(batchesCollection).InsertOne(ctx, claim); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("batch %d was claimed by another sealer", batch.ID)
+		}
+		return err
+	}
+
+	res, err := a.pool.Collection(collection).UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": batch.PriceIDs}, "batch": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"batch": batch.ID}})
+	if err == nil && int(res.MatchedCount) != len(batch.PriceIDs) {
+		err = fmt.Errorf("only %d of the %d prices of batch %d are unsealed", res.MatchedCount, len(batch.PriceIDs), batch.ID)
+	}
+	if err != nil {
+		if releaseErr := a.release(ctx, batch.ID); releaseErr != nil {
+			log.Err(releaseErr).Msgf("failed to release batch %d", batch.ID)
+		}
+		return err
+	}
+
+	replaced, err := a.pool.Collection(batchesCollection).ReplaceOne(ctx, claimFilter(batch.ID), batch)
+	if err != nil {
+		return err
+	}
+	if replaced.MatchedCount == 0 {
+		return fmt.Errorf("claim of batch %d expired before it was sealed", batch.ID)
+	}
+	log.Debug().Msgf("sealed %d prices into batch %d", res.ModifiedCount, batch.ID)
+	return nil
+}
+
+// releaseStale releases the claims older than claimTimeout.
+func (a *batches) releaseStale(ctx context.Context) error {
+	filter := bson.M{"claimed_at": bson.M{"$lte": time.Now().UTC().Add(-claimTimeout)}}
+	cursor, err := a.pool.Collection(batchesCollection).Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var stale []model.PriceBatch
+	if err = cursor.All(ctx, &stale); err != nil {
+		return err
+	}
+	for _, claim := range stale {
+		log.Warn().Msgf("releasing batch %d, its sealer didn't complete it", claim.ID)
+		if err = a.release(ctx, claim.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release drops the claim of id and unmarks its prices, a batch which was stored meanwhile is kept.
+func (a *batches) release(ctx context.Context, id int64) error {
+	res, err := a.pool.Collection(batchesCollection).DeleteOne(ctx, claimFilter(id))
+	if err != nil || res.DeletedCount == 0 {
+		return err
+	}
+	_, err = a.pool.Collection(collection).UpdateMany(ctx, bson.M{"batch": id}, bson.M{"$unset": bson.M{"batch": ""}})
+	return err
+}
+
+// claimFilter matches the placeholder of id until the batch replaces it.
+func claimFilter(id int64) bson.M {
+	return bson.M{"_id": id, "claimed_at": bson.M{"$exists": true}}
+}
+
+func (a *batches) Get(ctx context.Context, id int64) (*model.PriceBatch, error) {
+	var batch model.PriceBatch
+	filter := bson.M{"_id": id, "claimed_at": bson.M{"$exists": false}}
+	err := a.pool.Collection(batchesCollection).FindOne(ctx, filter).Decode(&batch)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (a *batches) FindPrice(ctx context.Context, seq uint64) (*model.Prices, error) {
+	var price model.Prices
+	err := a.pool.Collection(collection).FindOne(ctx, bson.M{"seq": seq}).Decode(&price)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (a *batches) Prices(ctx context.Context, batch *model.PriceBatch) ([]model.Prices, error) {
+	cursor, err := a.pool.Collection(collection).Find(ctx, bson.M{"_id": bson.M{"$in": batch.PriceIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []model.Prices
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]model.Prices, len(found))
+	for _, price := range found {
+		byID[price.ID.Hex()] = price
+	}
+	res := make([]model.Prices, 0, len(batch.PriceIDs))
+	for _, id := range batch.PriceIDs {
+		price, ok := byID[id.Hex()]
+		if !ok {
+			return nil, fmt.Errorf("price %s of batch %d is missing", id.Hex(), batch.ID)
+		}
+		res = append(res, price)
+	}
+	return res, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const candlesCollection = "candles"
+
+//go:generate mockery --name=Candles --structname=MockCandles --outpkg=repository --output ./mocks --filename candles_mock.go
+type Candles interface {
+	// Merge folds candle into the stored candle of the same asset, currency, interval and start and returns
+	// the result. Merging is idempotent and order independent, so late ticks and instances merging the same
+	// candle concurrently end up with the same candle.
+	Merge(ctx context.Context, candle *model.Candle) (*model.Candle, error)
+	// GetRange returns the latest query.Limit candles starting within the query range, oldest first.
+	GetRange(ctx context.Context, query CandleQuery) ([]*model.Candle, error)
+}
+
+// CandleQuery selects the candles of an asset in one currency and interval, both bounds are inclusive and
+// a zero one is open.
+type CandleQuery struct {
+	Asset    string
+	Currency string
+	Interval string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+type candles struct {
+	pool      *mongo.Database
+	indexOnce sync.Once
+}
+
+func NewCandles(conn *mongo.Database) *candles {
+	return &candles{
+		pool: conn,
+	}
+}
+
+func (a *candles) Merge(ctx context.Context, candle *model.Candle) (*model.Candle, error) {
+	a.ensureIndex(ctx)
+
+	filter := bson.M{"_id": candleID(candle.Asset, candle.Currency, candle.Interval, candle.Start)}
+	// expressions read the stored candle, a new one is taken as is
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"asset":    strings.ToUpper(candle.Asset),
+		"currency": strings.ToUpper(candle.Currency),
+		"interval": candle.Interval,
+		"start":    candle.Start,
+		"open": bson.M{"$cond": bson.A{
+			bson.M{"$lte": bson.A{candle.FirstTick, bson.M{"$ifNull": bson.A{"$first_tick", candle.FirstTick}}}},
+			candle.Open, "$open",
+		}},
+		"close": bson.M{"$cond": bson.A{
+			bson.M{"$gte": bson.A{candle.LastTick, bson.M{"$ifNull": bson.A{"$last_tick", candle.LastTick}}}},
+			candle.Close, "$close",
+		}},
+		"high":       bson.M{"$max": bson.A{"$high", candle.High}},
+		"low":        bson.M{"$min": bson.A{"$low", candle.Low}},
+		"first_tick": bson.M{"$min": bson.A{"$first_tick", candle.FirstTick}},
+		"last_tick":  bson.M{"$max": bson.A{"$last_tick", candle.LastTick}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var merged model.Candle
+	err := a.pool.Collection(candlesCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&merged)
+	if mongo.IsDuplicateKeyError(err) {
+		// another instance inserted the candle first, merge into theirs
+		err = a.pool.Collection(candlesCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&merged)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s %s/%s candle: %w", candle.Interval, candle.Asset, candle.Currency, err)
+	}
+	merged.Closed = candle.Closed
+	return &merged, nil
+}
+
+func (a *candles) GetRange(ctx context.Context, query CandleQuery) ([]*model.Candle, error) {
+	filter := bson.M{
+		"asset":    strings.ToUpper(query.Asset),
+		"currency": strings.ToUpper(query.Currency),
+		"interval": query.Interval,
+	}
+	start := bson.M{}
+	if !query.From.IsZero() {
+		start["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		start["$lte"] = query.To
+	}
+	if len(start) > 0 {
+		filter["start"] = start
+	}
+
+	opts := options.Find().SetSort(bson.M{"start": -1}).SetLimit(int64(query.Limit))
+	cursor, err := a.pool.Collection(candlesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbResult []*model.Candle
+	if err = cursor.All(ctx, &dbResult); err != nil {
+		return nil, err
+	}
+	res := make([]*model.Candle, 0, len(dbResult))
+	for i := len(dbResult) - 1; i >= 0; i-- {
+		res = append(res, dbResult[i])
+	}
+	return res, nil
+}
+
+// ensureIndex makes reading the candles of a chart cheap.
+func (a *candles) ensureIndex(ctx context.Context) {
+	a.indexOnce.Do(func() {
+		_, err := a.pool.Collection(candlesCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "asset", Value: 1},
+				{Key: "currency", Value: 1},
+				{Key: "interval", Value: 1},
+				{Key: "start", Value: -1},
+			},
+		})
+		if err != nil {
+			log.Err(err).Msg("failed to create candles index")
+		}
+	})
+}
+
+func candleID(asset, currency, interval string, start time.Time) string {
+	return fmt.Sprintf("%s/%s/%s/%d", strings.ToUpper(asset), strings.ToUpper(currency), interval, start.Unix())
+}
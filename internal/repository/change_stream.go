@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// watchRetryDelay is how long a broken change stream waits before it is reopened.
+const watchRetryDelay = time.Second
+
+type priceChange struct {
+	FullDocument model.Prices `bson:"fullDocument"`
+}
+
+// ChangeStream tails the prices inserted by any instance, change streams require a replica set.
+type ChangeStream struct {
+	pool *mongo.Database
+}
+
+func NewChangeStream(conn *mongo.Database) *ChangeStream {
+	return &ChangeStream{
+		pool: conn,
+	}
+}
+
+// Watch sends every price inserted from now on to prices and blocks until ctx is cancelled.
+// Failures are reported on errors and the stream resumes after the last price it delivered.
+func (c *ChangeStream) Watch(ctx context.Context, prices chan<- *model.CurrentPrice, errors chan<- error) error {
+	var resumeToken bson.Raw
+	for {
+		token, err := c.watch(ctx, resumeToken, prices)
+		if token != nil {
+			resumeToken = token
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			select {
+			case errors <- err:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-time.After(watchRetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// watch runs one change stream until it fails, it returns the token of the last delivered change.
+func (c *ChangeStream) watch(ctx context.Context, resumeAfter bson.Raw, prices chan<- *model.CurrentPrice) (bson.Raw, error) {
+	// sealing batches updates prices, only inserts are new prices
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	opts := options.ChangeStream()
+	if resumeAfter != nil {
+		opts.SetResumeAfter(resumeAfter)
+	}
+
+	stream, err := c.pool.Collection(collection).Watch(ctx, pipeline, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close(context.Background())
+	log.Info().Msgf("watching %s changes", collection)
+
+	var token bson.Raw
+	for stream.Next(ctx) {
+		var change priceChange
+		if err = stream.Decode(&change); err != nil {
+			log.Err(err).Msgf("skipping undecodable price change")
+			token = stream.ResumeToken()
+			continue
+		}
+
+		price := fromPrice(&change.FullDocument)
+		select {
+		case prices <- &price:
+			token = stream.ResumeToken()
+		case <-ctx.Done():
+			return token, ctx.Err()
+		}
+	}
+	return token, stream.Err()
+}
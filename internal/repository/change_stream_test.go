@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+
+	testdb "code.injective.org/service/pricefetcher/internal/repository/test_db"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ChangeStreamSuite struct {
+	suite.Suite
+	db       *mongo.Database
+	cleanups []func()
+}
+
+func (suite *ChangeStreamSuite) SetupSuite() {
+	db, cancel, err := testdb.NewMongoReplicaSet()
+	if err != nil {
+		suite.FailNow("Error creating replica set", fmt.Sprintf("%v", err))
+	}
+	suite.db = db
+	suite.cleanups = append(suite.cleanups, cancel)
+}
+
+func (suite *ChangeStreamSuite) TestWatchInsertedPrices() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prices := make(chan *model.CurrentPrice)
+	errors := make(chan error, 10)
+	go NewChangeStream(suite.db).Watch(ctx, prices, errors)
+	// the stream only sees what is inserted once it is open
+	time.Sleep(time.Second)
+
+	repo := NewPrices(suite.db)
+	createdDate := time.Unix(1706015736, 0)
+	err := repo.Create(ctx, &model.CurrentPrice{
+		Asset: "ETH",
+		Round: 7,
+		Time:  model.CurrentPriceTime{UpdatedISO: createdDate},
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Code: "USD", Value: model.MustParseDecimal("2250.5")},
+		},
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case price := <-prices:
+		suite.Assert().Equal("ETH", price.Asset)
+		suite.Assert().Equal(uint64(7), price.Round)
+		suite.Assert().Equal(createdDate.Unix(), price.Time.UpdatedISO.Unix())
+		usd, ok := price.Rate("USD")
+		suite.Assert().True(ok)
+		suite.Assert().Equal("2250.5", usd.Value.String())
+	case err = <-errors:
+		suite.FailNow("change stream failed", err.Error())
+	case <-time.After(10 * time.Second):
+		suite.FailNow("inserted price wasn't streamed")
+	}
+
+	// sealing updates prices, which must not be streamed again
+	batches := NewBatches(suite.db)
+	unsealed, err := batches.Unsealed(ctx, 100)
+	suite.Require().NoError(err)
+	batch := &model.PriceBatch{Root: "00"}
+	for _, price := range unsealed {
+		batch.PriceIDs = append(batch.PriceIDs, price.ID)
+	}
+	suite.Require().NoError(batches.Seal(ctx, batch))
+
+	select {
+	case price := <-prices:
+		suite.Failf("unexpected price", "%v", price)
+	case <-time.After(time.Second):
+	}
+}
+
+func (suite *ChangeStreamSuite) TearDownSuite() {
+	for i := range suite.cleanups {
+		suite.cleanups[i]()
+	}
+}
+
+func TestChangeStreamIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(ChangeStreamSuite))
+}
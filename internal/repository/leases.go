@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const leasesCollection = "leases"
+
+//go:generate mockery --name=Leases --structname=MockLeases --outpkg=repository --output ./mocks --filename leases_mock.go
+type Leases interface {
+	// Acquire takes or renews the lease for holder for ttl unless another holder's lease is still valid.
+	// It returns the lease as stored, whoever holds it.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*model.Lease, error)
+	// Release gives the lease up if holder owns it, so others don't have to wait for it to expire.
+	Release(ctx context.Context, name, holder string) error
+}
+
+type leases struct {
+	pool      *mongo.Database
+	indexOnce sync.Once
+}
+
+func NewLeases(conn *mongo.Database) *leases {
+	return &leases{
+		pool: conn,
+	}
+}
+
+func (a *leases) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*model.Lease, error) {
+	a.ensureIndex(ctx)
+
+	now := time.Now().UTC()
+	filter := bson.M{"_id": name, "$or": bson.A{
+		bson.M{"holder": holder},
+		bson.M{"expires_at": bson.M{"$lte": now}},
+	}}
+	// expressions read the stored document, so renewing keeps acquired_at and taking over resets it
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"holder":      holder,
+		"expires_at":  now.Add(ttl),
+		"acquired_at": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$holder", holder}}, "$acquired_at", now}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var lease model.Lease
+	err := a.pool.Collection(leasesCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&lease)
+	if mongo.IsDuplicateKeyError(err) {
+		// the upsert collided with a valid lease of another holder
+		err = a.pool.Collection(leasesCollection).FindOne(ctx, bson.M{"_id": name}).Decode(&lease)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+func (a *leases) Release(ctx context.Context, name, holder string) error {
+	_, err := a.pool.Collection(leasesCollection).DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
+
+// ensureIndex lets MongoDB remove leases abandoned by dead holders.
+func (a *leases) ensureIndex(ctx context.Context) {
+	a.indexOnce.Do(func() {
+		_, err := a.pool.Collection(leasesCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.M{"expires_at": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		})
+		if err != nil {
+			log.Err(err).Msg("failed to create leases TTL index")
+		}
+	})
+}
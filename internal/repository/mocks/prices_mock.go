@@ -8,6 +8,8 @@ import (
 	model "code.injective.org/service/pricefetcher/internal/model"
 	mock "github.com/stretchr/testify/mock"
 
+	repository "code.injective.org/service/pricefetcher/internal/repository"
+
 	time "time"
 )
 
@@ -30,25 +32,134 @@ func (_m *MockPrices) Create(ctx context.Context, in *model.CurrentPrice) error
 	return r0
 }
 
-// GetSinceDate provides a mock function with given fields: ctx, date
-func (_m *MockPrices) GetSinceDate(ctx context.Context, date time.Time) ([]*model.CurrentPrice, error) {
-	ret := _m.Called(ctx, date)
+// GetSinceDate provides a mock function with given fields: ctx, date, assets
+func (_m *MockPrices) GetSinceDate(ctx context.Context, date time.Time, assets []string) ([]*model.CurrentPrice, error) {
+	ret := _m.Called(ctx, date, assets)
 
 	var r0 []*model.CurrentPrice
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*model.CurrentPrice, error)); ok {
-		return rf(ctx, date)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, []string) ([]*model.CurrentPrice, error)); ok {
+		return rf(ctx, date, assets)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*model.CurrentPrice); ok {
-		r0 = rf(ctx, date)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, []string) []*model.CurrentPrice); ok {
+		r0 = rf(ctx, date, assets)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.CurrentPrice)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
-		r1 = rf(ctx, date)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, []string) error); ok {
+		r1 = rf(ctx, date, assets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSinceSeq provides a mock function with given fields: ctx, seq, assets
+func (_m *MockPrices) GetSinceSeq(ctx context.Context, seq uint64, assets []string) ([]*model.CurrentPrice, error) {
+	ret := _m.Called(ctx, seq, assets)
+
+	var r0 []*model.CurrentPrice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []string) ([]*model.CurrentPrice, error)); ok {
+		return rf(ctx, seq, assets)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []string) []*model.CurrentPrice); ok {
+		r0 = rf(ctx, seq, assets)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CurrentPrice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, []string) error); ok {
+		r1 = rf(ctx, seq, assets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRange provides a mock function with given fields: ctx, query
+func (_m *MockPrices) GetRange(ctx context.Context, query repository.PriceQuery) ([]*model.CurrentPrice, string, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*model.CurrentPrice
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PriceQuery) ([]*model.CurrentPrice, string, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.PriceQuery) []*model.CurrentPrice); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CurrentPrice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.PriceQuery) string); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.PriceQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Latest provides a mock function with given fields: ctx, assets
+func (_m *MockPrices) Latest(ctx context.Context, assets []string) ([]*model.CurrentPrice, error) {
+	ret := _m.Called(ctx, assets)
+
+	var r0 []*model.CurrentPrice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*model.CurrentPrice, error)); ok {
+		return rf(ctx, assets)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.CurrentPrice); ok {
+		r0 = rf(ctx, assets)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CurrentPrice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, assets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LastRound provides a mock function with given fields: ctx, asset
+func (_m *MockPrices) LastRound(ctx context.Context, asset string) (uint64, error) {
+	ret := _m.Called(ctx, asset)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uint64, error)); ok {
+		return rf(ctx, asset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uint64); ok {
+		r0 = rf(ctx, asset)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, asset)
 	} else {
 		r1 = ret.Error(1)
 	}
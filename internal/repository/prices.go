@@ -2,24 +2,61 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"code.injective.org/service/pricefetcher/internal/model"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const collection = "prices"
+const (
+	collection         = "prices"
+	countersCollection = "counters"
+)
 
 //go:generate mockery --name=Prices --structname=MockPrices --outpkg=repository --output ./mocks --filename prices_mock.go
 type Prices interface {
 	Create(ctx context.Context, in *model.CurrentPrice) error
-	GetSinceDate(ctx context.Context, date time.Time) ([]*model.CurrentPrice, error)
+	// GetSinceDate returns prices of the given assets created after date, all assets when assets is empty.
+	GetSinceDate(ctx context.Context, date time.Time, assets []string) ([]*model.CurrentPrice, error)
+	// GetSinceSeq returns prices of the given assets stored after seq ordered by seq, all assets when assets is empty.
+	GetSinceSeq(ctx context.Context, seq uint64, assets []string) ([]*model.CurrentPrice, error)
+	// Latest returns the most recent price of each of the given assets, of every stored asset when assets is empty.
+	Latest(ctx context.Context, assets []string) ([]*model.CurrentPrice, error)
+	// GetRange returns a page of the prices matching query ordered by creation time, and the cursor of the next
+	// page, empty on the last one.
+	GetRange(ctx context.Context, query PriceQuery) ([]*model.CurrentPrice, string, error)
+	// LastRound returns the highest round stored for asset, 0 when none was published yet.
+	LastRound(ctx context.Context, asset string) (uint64, error)
+}
+
+// ErrInvalidCursor is returned for a page cursor GetRange didn't hand out.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PriceQuery selects stored prices by creation time, both bounds are inclusive and a zero one is open.
+type PriceQuery struct {
+	From   time.Time
+	To     time.Time
+	Assets []string
+	// Currencies keeps the prices quoting at least one of them, any price when empty.
+	Currencies []string
+	Limit      int
+	// Cursor continues the query after the page it was returned with.
+	Cursor string
 }
 
 type prices struct {
-	pool *mongo.Database
+	pool      *mongo.Database
+	indexOnce sync.Once
 }
 
 func NewPrices(conn *mongo.Database) *prices {
@@ -28,17 +65,31 @@ func NewPrices(conn *mongo.Database) *prices {
 	}
 }
 
+// Create stores in under the next sequence number and sets it on in. Numbers are taken before the insert, so prices
+// are only stored in sequence order by a single writer.
 func (a *prices) Create(ctx context.Context, in *model.CurrentPrice) error {
-	res, err := a.pool.Collection(collection).InsertOne(ctx, a.toPrice(in))
+	a.ensureIndexes(ctx)
+	seq, err := a.nextSeq(ctx)
 	if err != nil {
 		return err
 	}
+
+	doc := toPrice(in)
+	doc.Seq = seq
+	res, err := a.pool.Collection(collection).InsertOne(ctx, doc)
+	if err != nil {
+		return err
+	}
+	in.Seq = seq
 	log.Debug().Msgf("inserted new collection with ID %d", res)
 	return nil
 }
 
-func (a *prices) GetSinceDate(ctx context.Context, sinceDate time.Time) ([]*model.CurrentPrice, error) {
+func (a *prices) GetSinceDate(ctx context.Context, sinceDate time.Time, assets []string) ([]*model.CurrentPrice, error) {
 	filter := bson.M{"created_at": bson.M{"$gt": sinceDate.UTC().Unix()}}
+	if len(assets) > 0 {
+		filter["$or"] = assetsFilter(assets)
+	}
 	cursor, err := a.pool.Collection(collection).Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -52,75 +103,283 @@ func (a *prices) GetSinceDate(ctx context.Context, sinceDate time.Time) ([]*mode
 	var res []*model.CurrentPrice
 	for _, result := range dbResult {
 		cursor.Decode(&result)
-		curPrice := a.fromPrice(&result)
+		curPrice := fromPrice(&result)
+		res = append(res, &curPrice)
+	}
+
+	return res, nil
+}
+
+func (a *prices) GetSinceSeq(ctx context.Context, seq uint64, assets []string) ([]*model.CurrentPrice, error) {
+	filter := bson.M{"seq": bson.M{"$gt": seq}}
+	if len(assets) > 0 {
+		filter["$or"] = assetsFilter(assets)
+	}
+	cursor, err := a.pool.Collection(collection).Find(ctx, filter, options.Find().SetSort(bson.M{"seq": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var dbResult []model.Prices
+	if err = cursor.All(ctx, &dbResult); err != nil {
+		return nil, err
+	}
+
+	res := make([]*model.CurrentPrice, 0, len(dbResult))
+	for i := range dbResult {
+		curPrice := fromPrice(&dbResult[i])
 		res = append(res, &curPrice)
 	}
+	return res, nil
+}
+
+func (a *prices) Latest(ctx context.Context, assets []string) ([]*model.CurrentPrice, error) {
+	var pipeline mongo.Pipeline
+	if len(assets) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": assetsFilter(assets)}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		// documents stored without asset belong to model.DefaultAsset
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$ifNull": bson.A{"$asset", model.DefaultAsset}},
+			"doc": bson.M{"$first": "$$ROOT"},
+		}}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+		bson.D{{Key: "$sort", Value: bson.M{"asset": 1}}},
+	)
+	cursor, err := a.pool.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
 
+	var dbResult []model.Prices
+	if err = cursor.All(ctx, &dbResult); err != nil {
+		return nil, err
+	}
+
+	res := make([]*model.CurrentPrice, 0, len(dbResult))
+	for i := range dbResult {
+		curPrice := fromPrice(&dbResult[i])
+		res = append(res, &curPrice)
+	}
 	return res, nil
 }
 
-func (a *prices) toPrice(in *model.CurrentPrice) model.Prices {
+func (a *prices) GetRange(ctx context.Context, query PriceQuery) ([]*model.CurrentPrice, string, error) {
+	a.ensureIndexes(ctx)
+	var filter bson.A
+	createdAt := bson.M{}
+	if !query.From.IsZero() {
+		createdAt["$gte"] = query.From.UTC().Unix()
+	}
+	if !query.To.IsZero() {
+		createdAt["$lte"] = query.To.UTC().Unix()
+	}
+	if len(createdAt) > 0 {
+		filter = append(filter, bson.M{"created_at": createdAt})
+	}
+	if len(query.Assets) > 0 {
+		filter = append(filter, bson.M{"$or": assetsFilter(query.Assets)})
+	}
+	if len(query.Currencies) > 0 {
+		filter = append(filter, bson.M{"$or": currenciesFilter(query.Currencies)})
+	}
+	if query.Cursor != "" {
+		after, id, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter = append(filter, bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{"$gt": after}},
+			bson.M{"created_at": after, "_id": bson.M{"$gt": id}},
+		}})
+	}
+	match := bson.M{}
+	if len(filter) > 0 {
+		match["$and"] = filter
+	}
+
+	// one more than asked for tells whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit) + 1)
+	cursor, err := a.pool.Collection(collection).Find(ctx, match, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var dbResult []model.Prices
+	if err = cursor.All(ctx, &dbResult); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(dbResult) > query.Limit {
+		dbResult = dbResult[:query.Limit]
+		last := dbResult[len(dbResult)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	res := make([]*model.CurrentPrice, 0, len(dbResult))
+	for i := range dbResult {
+		curPrice := fromPrice(&dbResult[i])
+		res = append(res, &curPrice)
+	}
+	return res, next, nil
+}
+
+// encodeCursor points after the price created at createdAt with the given ID, IDs order prices created at the same time.
+func encodeCursor(createdAt int64, id primitive.ObjectID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", createdAt, id.Hex())))
+}
+
+func decodeCursor(cursor string) (int64, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, primitive.NilObjectID, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, primitive.NilObjectID, ErrInvalidCursor
+	}
+	after, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return 0, primitive.NilObjectID, ErrInvalidCursor
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, primitive.NilObjectID, ErrInvalidCursor
+	}
+	return after, objectID, nil
+}
+
+func (a *prices) LastRound(ctx context.Context, asset string) (uint64, error) {
+	filter := bson.M{"$or": assetsFilter([]string{asset}), "round": bson.M{"$exists": true}}
+	opts := options.FindOne().SetSort(bson.M{"round": -1}).SetProjection(bson.M{"round": 1})
+
+	var last model.Prices
+	err := a.pool.Collection(collection).FindOne(ctx, filter, opts).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.Round, nil
+}
+
+// nextSeq increments the prices counter.
+func (a *prices) nextSeq(ctx context.Context) (uint64, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := a.pool.Collection(countersCollection).
+		FindOneAndUpdate(ctx, bson.M{"_id": collection}, bson.M{"$inc": bson.M{"seq": int64(1)}}, opts).
+		Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign price sequence number: %w", err)
+	}
+	return uint64(counter.Seq), nil
+}
+
+// ensureIndexes makes resuming by sequence number and paging by creation time cheap.
+func (a *prices) ensureIndexes(ctx context.Context) {
+	a.indexOnce.Do(func() {
+		_, err := a.pool.Collection(collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.M{"seq": 1},
+				Options: options.Index().SetSparse(true),
+			},
+			{
+				Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+			},
+		})
+		if err != nil {
+			log.Err(err).Msg("failed to create prices indexes")
+		}
+	})
+}
+
+// assetsFilter matches the given assets, documents stored without asset belong to model.DefaultAsset.
+// currenciesFilter matches prices quoting one of currencies.
+func currenciesFilter(currencies []string) bson.A {
+	filter := make(bson.A, 0, len(currencies))
+	for _, code := range currencies {
+		filter = append(filter, bson.M{"price.bpi." + strings.ToUpper(code): bson.M{"$exists": true}})
+	}
+	return filter
+}
+
+func assetsFilter(assets []string) bson.A {
+	symbols := make(bson.A, 0, len(assets))
+	legacy := false
+	for _, asset := range assets {
+		asset = strings.ToUpper(asset)
+		symbols = append(symbols, asset)
+		legacy = legacy || asset == model.DefaultAsset
+	}
+
+	filter := bson.A{bson.M{"asset": bson.M{"$in": symbols}}}
+	if legacy {
+		filter = append(filter, bson.M{"asset": bson.M{"$exists": false}})
+	}
+	return filter
+}
+
+func toPrice(in *model.CurrentPrice) model.Prices {
+	bpi := make(map[string]model.PricesCurrentPriceRate, len(in.Bpi))
+	for code, rate := range in.Bpi {
+		bpi[strings.ToUpper(code)] = model.PricesCurrentPriceRate{
+			Code:        rate.Code,
+			Symbol:      rate.Symbol,
+			Rate:        rate.Rate,
+			Description: rate.Description,
+			Value:       rate.Value,
+		}
+	}
+
 	return model.Prices{
+		Round:     in.Round,
+		Seq:       in.Seq,
+		Asset:     in.AssetSymbol(),
 		CreatedAt: in.Time.UpdatedISO.UTC().Unix(),
 		Price: model.PricesInfo{
 			Disclaimer: in.Disclaimer,
 			ChartName:  in.ChartName,
-			Bpi: model.PricesCurrentPriceBpi{
-				Usd: model.PricesCurrentPriceRate{
-					Code:        in.Bpi.Usd.Code,
-					Symbol:      in.Bpi.Usd.Symbol,
-					Rate:        in.Bpi.Usd.Rate,
-					Description: in.Bpi.Usd.Description,
-					RateFloat:   in.Bpi.Usd.RateFloat,
-				},
-				Gbp: model.PricesCurrentPriceRate{
-					Code:        in.Bpi.Gbp.Code,
-					Symbol:      in.Bpi.Gbp.Symbol,
-					Rate:        in.Bpi.Gbp.Rate,
-					Description: in.Bpi.Gbp.Description,
-					RateFloat:   in.Bpi.Gbp.RateFloat,
-				},
-				Eur: model.PricesCurrentPriceRate{
-					Code:        in.Bpi.Eur.Code,
-					Symbol:      in.Bpi.Eur.Symbol,
-					Rate:        in.Bpi.Eur.Rate,
-					Description: in.Bpi.Eur.Description,
-					RateFloat:   in.Bpi.Eur.RateFloat,
-				},
-			},
+			Bpi:        bpi,
+			Sources:    in.Sources,
 		},
 	}
 }
 
-func (a *prices) fromPrice(in *model.Prices) model.CurrentPrice {
+func fromPrice(in *model.Prices) model.CurrentPrice {
+	asset := in.Asset
+	if asset == "" {
+		asset = model.DefaultAsset
+	}
+
+	bpi := make(map[string]model.CurrentPriceRate, len(in.Price.Bpi))
+	for code, rate := range in.Price.Bpi {
+		bpi[code] = model.CurrentPriceRate{
+			Code:        rate.Code,
+			Symbol:      rate.Symbol,
+			Rate:        rate.Rate,
+			Description: rate.Description,
+			Value:       rate.Value,
+		}
+	}
+
 	return model.CurrentPrice{
+		Asset: asset,
 		Time: model.CurrentPriceTime{
 			UpdatedISO: time.Unix(in.CreatedAt, 10),
 		},
 		Disclaimer: in.Price.Disclaimer,
 		ChartName:  in.Price.ChartName,
-		Bpi: model.CurrentPriceBpi{
-			Usd: model.CurrentPriceRate{
-				Code:        in.Price.Bpi.Usd.Code,
-				Symbol:      in.Price.Bpi.Usd.Symbol,
-				Rate:        in.Price.Bpi.Usd.Rate,
-				Description: in.Price.Bpi.Usd.Description,
-				RateFloat:   in.Price.Bpi.Usd.RateFloat,
-			},
-			Gbp: model.CurrentPriceRate{
-				Code:        in.Price.Bpi.Gbp.Code,
-				Symbol:      in.Price.Bpi.Gbp.Symbol,
-				Rate:        in.Price.Bpi.Gbp.Rate,
-				Description: in.Price.Bpi.Gbp.Description,
-				RateFloat:   in.Price.Bpi.Gbp.RateFloat,
-			},
-			Eur: model.CurrentPriceRate{
-				Code:        in.Price.Bpi.Eur.Code,
-				Symbol:      in.Price.Bpi.Eur.Symbol,
-				Rate:        in.Price.Bpi.Eur.Rate,
-				Description: in.Price.Bpi.Eur.Description,
-				RateFloat:   in.Price.Bpi.Eur.RateFloat,
-			},
-		},
+		Bpi:        bpi,
+		Sources:    in.Price.Sources,
+		Round:      in.Round,
+		Seq:        in.Seq,
 	}
 }
@@ -10,6 +10,8 @@ import (
 
 	testdb "code.injective.org/service/pricefetcher/internal/repository/test_db"
 	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -40,35 +42,252 @@ func (suite *PricesRepositorySuite) TestCreate() {
 		Time:       model.CurrentPriceTime{UpdatedISO: createdDate},
 		Disclaimer: "disclamer",
 		ChartName:  "chart_name",
-		Bpi: model.CurrentPriceBpi{
-			Usd: model.CurrentPriceRate{
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {
 				Code:        "USD",
 				Symbol:      "USD",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
-			Gbp: model.CurrentPriceRate{
+			"GBP": {
 				Code:        "GBP",
 				Symbol:      "GBP",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
-			Eur: model.CurrentPriceRate{
+			"EUR": {
 				Code:        "EUR",
 				Symbol:      "EUR",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
 		},
 	})
 	suite.Assert().NoError(err)
 
-	result, err := suite.repository.GetSinceDate(ctx, createdDate.Add(-1*time.Minute))
+	result, err := suite.repository.GetSinceDate(ctx, createdDate.Add(-1*time.Minute), nil)
 	suite.Assert().Len(result, 1)
 	suite.Assert().NoError(err)
+	suite.Assert().Equal(model.DefaultAsset, result[0].Asset)
+}
+
+func (suite *PricesRepositorySuite) TestGetSinceDateByAsset() {
+	ctx := context.Background()
+	createdDate := time.Now().Add(time.Hour)
+
+	for _, asset := range []string{"ETH", "INJ"} {
+		err := suite.repository.Create(ctx, &model.CurrentPrice{
+			Asset: asset,
+			Time:  model.CurrentPriceTime{UpdatedISO: createdDate},
+		})
+		suite.Assert().NoError(err)
+	}
+
+	result, err := suite.repository.GetSinceDate(ctx, createdDate.Add(-1*time.Minute), []string{"eth"})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 1)
+	suite.Assert().Equal("ETH", result[0].Asset)
+
+	result, err = suite.repository.GetSinceDate(ctx, createdDate.Add(-1*time.Minute), []string{"ETH", "INJ"})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 2)
+}
+
+func (suite *PricesRepositorySuite) TestGetSinceSeq() {
+	ctx := context.Background()
+	var created []*model.CurrentPrice
+	for _, asset := range []string{"ADA", "DOT", "ADA"} {
+		price := &model.CurrentPrice{Asset: asset, Time: model.CurrentPriceTime{UpdatedISO: time.Now()}}
+		suite.Assert().NoError(suite.repository.Create(ctx, price))
+		created = append(created, price)
+	}
+	suite.Assert().Equal(created[0].Seq+1, created[1].Seq)
+	suite.Assert().Equal(created[1].Seq+1, created[2].Seq)
+
+	result, err := suite.repository.GetSinceSeq(ctx, created[0].Seq, nil)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 2)
+	suite.Assert().Equal(created[1].Seq, result[0].Seq)
+	suite.Assert().Equal(created[2].Seq, result[1].Seq)
+
+	result, err = suite.repository.GetSinceSeq(ctx, created[0].Seq, []string{"ada"})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 1)
+	suite.Assert().Equal(created[2].Seq, result[0].Seq)
+}
+
+func (suite *PricesRepositorySuite) TestGetRange() {
+	ctx := context.Background()
+	createdDate := time.Now().Add(3 * time.Hour)
+	// two prices share the second the first page ends on, neither may be lost or repeated
+	for i, asset := range []string{"XRP", "XRP", "LTC", "XRP"} {
+		at := createdDate.Add(time.Duration(i) * time.Second)
+		if i == 2 {
+			at = createdDate.Add(time.Second)
+		}
+		suite.Assert().NoError(suite.repository.Create(ctx, &model.CurrentPrice{Asset: asset, Time: model.CurrentPriceTime{UpdatedISO: at}}))
+	}
+
+	query := PriceQuery{From: createdDate, To: createdDate.Add(3 * time.Second), Assets: []string{"xrp", "ltc"}, Limit: 2}
+	first, next, err := suite.repository.GetRange(ctx, query)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(first, 2)
+	suite.Assert().NotEmpty(next)
+
+	query.Cursor = next
+	second, next, err := suite.repository.GetRange(ctx, query)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(second, 2)
+	suite.Assert().Empty(next)
+	seen := make(map[uint64]bool)
+	for _, price := range append(first, second...) {
+		suite.Assert().False(seen[price.Seq])
+		seen[price.Seq] = true
+	}
+
+	// the upper bound is inclusive
+	result, _, err := suite.repository.GetRange(ctx, PriceQuery{From: createdDate.Add(time.Second), To: createdDate.Add(time.Second),
+		Assets: []string{"XRP"}, Limit: 10})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 1)
+
+	_, _, err = suite.repository.GetRange(ctx, PriceQuery{Limit: 10, Cursor: "bad"})
+	suite.Assert().ErrorIs(err, ErrInvalidCursor)
+
+	// prices not quoting a requested currency don't take up the page
+	quotedAt := createdDate.Add(10 * time.Second)
+	for _, code := range []string{"EUR", "USD"} {
+		price := &model.CurrentPrice{Asset: "ADA", Time: model.CurrentPriceTime{UpdatedISO: quotedAt}}
+		price.SetRate(code, model.CurrentPriceRate{Value: model.NewDecimal(1, 0)})
+		suite.Assert().NoError(suite.repository.Create(ctx, price))
+	}
+	result, next, err = suite.repository.GetRange(ctx, PriceQuery{From: quotedAt, To: quotedAt, Assets: []string{"ADA"},
+		Currencies: []string{"usd"}, Limit: 1})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 1)
+	suite.Assert().Empty(next)
+	_, ok := result[0].Rate("USD")
+	suite.Assert().True(ok)
+}
+
+func (suite *PricesRepositorySuite) TestLatest() {
+	ctx := context.Background()
+	createdDate := time.Now().Add(4 * time.Hour)
+	for i, asset := range []string{"ATOM", "OSMO", "ATOM"} {
+		err := suite.repository.Create(ctx, &model.CurrentPrice{
+			Asset: asset,
+			Time:  model.CurrentPriceTime{UpdatedISO: createdDate.Add(time.Duration(i) * time.Second)},
+		})
+		suite.Assert().NoError(err)
+	}
+
+	result, err := suite.repository.Latest(ctx, []string{"atom", "osmo"})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(result, 2)
+	suite.Assert().Equal("ATOM", result[0].Asset)
+	suite.Assert().Equal(createdDate.Add(2*time.Second).Unix(), result[0].Time.UpdatedISO.Unix())
+	suite.Assert().Equal("OSMO", result[1].Asset)
+}
+
+func (suite *PricesRepositorySuite) TestSealBatch() {
+	ctx := context.Background()
+	createdDate := time.Now().Add(2 * time.Hour)
+	batches := NewBatches(suite.db)
+
+	// two ticks within the same second are told apart by their seq
+	sol := &model.CurrentPrice{Asset: "SOL", Time: model.CurrentPriceTime{UpdatedISO: createdDate}}
+	suite.Assert().NoError(suite.repository.Create(ctx, sol))
+	sameSecond := &model.CurrentPrice{Asset: "SOL", Time: model.CurrentPriceTime{UpdatedISO: createdDate}}
+	suite.Assert().NoError(suite.repository.Create(ctx, sameSecond))
+
+	unsealed, err := batches.Unsealed(ctx, 100)
+	suite.Assert().NoError(err)
+	suite.Assert().NotEmpty(unsealed)
+
+	batch := &model.PriceBatch{Root: "00"}
+	for _, price := range unsealed {
+		batch.PriceIDs = append(batch.PriceIDs, price.ID)
+	}
+	suite.Assert().NoError(batches.Seal(ctx, batch))
+	suite.Assert().NotZero(batch.ID)
+
+	price, err := batches.FindPrice(ctx, sol.Seq)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(batch.ID, price.Batch)
+	other, err := batches.FindPrice(ctx, sameSecond.Seq)
+	suite.Assert().NoError(err)
+	suite.Assert().NotEqual(price.ID, other.ID)
+
+	sealed, err := batches.Prices(ctx, batch)
+	suite.Assert().NoError(err)
+	suite.Assert().Len(sealed, len(unsealed))
+
+	unsealed, err = batches.Unsealed(ctx, 100)
+	suite.Assert().NoError(err)
+	suite.Assert().Empty(unsealed)
+
+	// a sealer which died after marking leaves a claim, its prices are released once it is stale
+	claimed := batch.ID + 1
+	_, err = suite.db.Collection(batchesCollection).InsertOne(ctx,
+		bson.M{"_id": claimed, "claimed_at": time.Now().UTC().Add(-2 * claimTimeout)})
+	suite.Assert().NoError(err)
+	_, err = suite.db.Collection(collection).UpdateByID(ctx, price.ID, bson.M{"$set": bson.M{"batch": claimed}})
+	suite.Assert().NoError(err)
+	_, err = batches.Get(ctx, claimed)
+	suite.Assert().ErrorIs(err, ErrNotFound)
+
+	next := &model.PriceBatch{Root: "01", PriceIDs: []primitive.ObjectID{price.ID}}
+	suite.Assert().NoError(batches.Seal(ctx, next))
+	suite.Assert().Equal(claimed, next.ID)
+	stored, err := batches.Get(ctx, claimed)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("01", stored.Root)
+
+	// sealing a sealed price fails without leaving a claim or touching the price
+	suite.Assert().Error(batches.Seal(ctx, &model.PriceBatch{Root: "02", PriceIDs: []primitive.ObjectID{price.ID}}))
+	count, err := suite.db.Collection(batchesCollection).CountDocuments(ctx, bson.M{"_id": claimed + 1})
+	suite.Assert().NoError(err)
+	suite.Assert().Zero(count)
+	price, err = batches.FindPrice(ctx, sol.Seq)
+	suite.Assert().NoError(err)
+	suite.Assert().Equal(claimed, price.Batch)
+
+	_, err = batches.FindPrice(ctx, 0)
+	suite.Assert().ErrorIs(err, ErrNotFound)
+}
+
+func (suite *PricesRepositorySuite) TestMergeCandles() {
+	ctx := context.Background()
+	repo := NewCandles(suite.db)
+	start := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	candle := func(second int, value int64) *model.Candle {
+		c := &model.Candle{Asset: "btc", Currency: "usd", Interval: "1m", Start: start}
+		c.Add(start.Add(time.Duration(second)*time.Second), model.NewDecimal(value, 0))
+		return c
+	}
+
+	merged, err := repo.Merge(ctx, candle(10, 100))
+	suite.Assert().NoError(err)
+	suite.Assert().Equal("100", merged.Open.String())
+
+	// late ticks before the first one, after the last one and in between, merged twice
+	for i := 0; i < 2; i++ {
+		for _, late := range []*model.Candle{candle(5, 90), candle(50, 110), candle(20, 130)} {
+			merged, err = repo.Merge(ctx, late)
+			suite.Assert().NoError(err)
+		}
+	}
+	suite.Assert().Equal([]string{"90", "130", "90", "110"},
+		[]string{merged.Open.String(), merged.High.String(), merged.Low.String(), merged.Close.String()})
+
+	candles, err := repo.GetRange(ctx, CandleQuery{Asset: "BTC", Currency: "USD", Interval: "1m", From: start, To: start, Limit: 10})
+	suite.Assert().NoError(err)
+	suite.Assert().Len(candles, 1)
+	suite.Assert().Equal("BTC", candles[0].Asset)
+	suite.Assert().True(start.Equal(candles[0].Start))
 }
 
 func (suite *PricesRepositorySuite) TearDownSuite() {
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const quarantineCollection = "quarantine"
+
+//go:generate mockery --name=Quarantine --structname=MockQuarantine --outpkg=repository --output ./mocks --filename quarantine_mock.go
+type Quarantine interface {
+	Create(ctx context.Context, in *model.CurrentPrice, reason string) error
+}
+
+type quarantine struct {
+	pool *mongo.Database
+}
+
+func NewQuarantine(conn *mongo.Database) *quarantine {
+	return &quarantine{
+		pool: conn,
+	}
+}
+
+func (a *quarantine) Create(ctx context.Context, in *model.CurrentPrice, reason string) error {
+	doc := model.QuarantinedPrice{
+		Prices:     toPrice(in),
+		Reason:     reason,
+		RejectedAt: time.Now().UTC().Unix(),
+	}
+	res, err := a.pool.Collection(quarantineCollection).InsertOne(ctx, doc)
+	if err != nil {
+		return err
+	}
+	log.Debug().Msgf("quarantined price with ID %v", res.InsertedID)
+	return nil
+}
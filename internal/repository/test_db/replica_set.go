@@ -0,0 +1,96 @@
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ReplicaSetName = "rs0"
+
+// NewMongoReplicaSet starts a single node replica set, which change streams require.
+func NewMongoReplicaSet() (*mongo.Database, func(), error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to docker: %v", err)
+	}
+	options := &dockertest.RunOptions{
+		Repository: Image,
+		Tag:        Version,
+		// replica set members authenticate with a key file, so the test node runs without users
+		Cmd:          []string{"--replSet", ReplicaSetName, "--bind_ip_all"},
+		Labels:       map[string]string{"goose_test": "1"},
+		ExposedPorts: []string{"27017"},
+	}
+
+	container, err := pool.RunWithOptions(
+		options,
+		func(config *docker.HostConfig) {
+			// Set AutoRemove to true so that stopped container goes away by itself.
+			config.AutoRemove = true
+			config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+		},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create docker container: %v", err)
+	}
+	cleanup := func() {
+		if err := pool.Purge(container); err != nil {
+			log.Printf("failed to purge resource: %v", err)
+		}
+	}
+
+	// the member is reached through the mapped port, so the driver must not use the replica set topology
+	mongoURL := fmt.Sprintf("mongodb://localhost:%s/?directConnection=true", container.GetPort("27017/tcp"))
+	var mongoClient *mongo.Client
+	err = pool.Retry(func() error {
+		ctx := context.Background()
+		mongoClient, err = mongo.Connect(ctx, mOptions.Client().ApplyURI(mongoURL))
+		if err != nil {
+			return err
+		}
+		return mongoClient.Ping(ctx, nil)
+	})
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to connect to mongo: %v", err)
+	}
+
+	ctx := context.Background()
+	err = mongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: bson.M{
+		"_id":     ReplicaSetName,
+		"members": bson.A{bson.M{"_id": 0, "host": "localhost:27017"}},
+	}}}).Err()
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to initiate replica set: %v", err)
+	}
+
+	// writes and change streams need the node to be elected first
+	pool.MaxWait = time.Minute
+	err = pool.Retry(func() error {
+		var hello struct {
+			IsWritablePrimary bool `bson:"isWritablePrimary"`
+		}
+		if err := mongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			return err
+		}
+		if !hello.IsWritablePrimary {
+			return fmt.Errorf("replica set has no primary yet")
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("replica set wasn't elected: %v", err)
+	}
+
+	return mongoClient.Database(MongoDBName), cleanup, nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.injective.org/service/pricefetcher/internal/candle"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultCandleLimit = 500
+
+// WithCandles serves the candles built by aggregator on /candles and their live updates on /ws/candles.
+func (s *Server) WithCandles(aggregator *candle.Aggregator) {
+	s.candles = aggregator
+}
+
+// candlesHandler serves /candles?asset=BTC&currency=USD&interval=1m&from=1705938898&to=1706015736&limit=500,
+// the latest candles starting within the inclusive unix time bounds, oldest first. Bounds may be omitted, the
+// asset defaults to BTC and the currency to USD.
+func (s *Server) candlesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	queryMap := r.URL.Query()
+	query := repository.CandleQuery{
+		Asset:    queryMap.Get("asset"),
+		Currency: queryMap.Get("currency"),
+		Limit:    defaultCandleLimit,
+	}
+	if query.Asset == "" {
+		query.Asset = model.DefaultAsset
+	}
+	if query.Currency == "" {
+		query.Currency = "USD"
+	}
+	if unknown := model.UnknownCurrencies([]string{query.Currency}); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+	interval, err := s.candles.Interval(queryMap.Get("interval"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query.Interval = string(interval)
+	if query.From, err = queryTime(queryMap, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.To, err = queryTime(queryMap, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if queryMap.Has("limit") {
+		query.Limit, err = strconv.Atoi(queryMap.Get("limit"))
+		if err != nil || query.Limit < 1 || query.Limit > maxPageSize {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	candles, err := s.candles.Candles(r.Context(), query)
+	if err != nil {
+		log.Err(err).Msg("error reading candles")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if candles == nil {
+		candles = []*model.Candle{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{"candles": candles}); err != nil {
+		log.Err(err).Msg("error writing candles")
+	}
+}
+
+// candlesWsHandler streams the candles in progress of /ws/candles?interval=1m&asset=BTC&currency=USD, every
+// asset and currency when omitted. Clients get the current candles first, then every update, and a candle
+// with "closed": true once its interval is over or a late tick corrected it.
+func (s *Server) candlesWsHandler(w http.ResponseWriter, r *http.Request) {
+	currency, assets := queryFilter(r.URL.Query())
+	if unknown := model.UnknownCurrencies(currency); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+	interval, err := s.candles.Interval(r.URL.Query().Get("interval"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Err(err).Msg("error upgrading connection")
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	sub := s.candles.Subscribe(ctx, interval, assets, currency)
+	defer sub.Close()
+
+	// clients don't send anything, reading notices when they leave
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, current := range sub.Current() {
+		if err = conn.WriteJSON(current); err != nil {
+			log.Err(err).Msg("error writing message to client")
+			return
+		}
+	}
+	// the subscription is closed when the client goes away
+	for update := range sub.C() {
+		if err = conn.WriteJSON(update); err != nil {
+			log.Err(err).Msg("error writing message to client")
+			return
+		}
+	}
+}
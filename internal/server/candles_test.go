@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/candle"
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// ahead of the clock, so the candles aren't closed while the test runs
+	start := time.Now().UTC().Add(2 * time.Hour).Truncate(time.Hour)
+	tick := func(asset string, usd int64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: asset, Time: model.CurrentPriceTime{UpdatedISO: start.Add(time.Second)},
+			Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(usd, 0)}}}
+	}
+
+	// candles in progress are stored when the aggregator stops
+	repo := mockRepo.NewMockCandles(t)
+	repo.On("Merge", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, c *model.Candle) (*model.Candle, error) { return c, nil }).Maybe()
+	priceHub := hub.New(nil, nil, nil)
+	aggregator := candle.NewAggregator(repo, []candle.Interval{candle.Minute, candle.Hour})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		aggregator.Run(ctx, priceHub, 0)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+	require.Eventually(t, func() bool { return priceHub.Count() == 1 }, time.Second, 10*time.Millisecond)
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+	srv.WithCandles(aggregator)
+
+	priceHub.Publish(tick("BTC", 100))
+	require.Eventually(t, func() bool {
+		_, ok := aggregator.Current("BTC", "USD", candle.Minute)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	repo.On("GetRange", mock.Anything, repository.CandleQuery{Asset: "BTC", Currency: "USD", Interval: "1h", Limit: 10}).
+		Return(nil, nil).Once()
+	code, body := get(t, srv.candlesHandler, "/candles?interval=1h&limit=10")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, body["candles"], 1)
+	require.Equal(t, "1h", body["candles"].([]interface{})[0].(map[string]interface{})["interval"])
+	for _, target := range []string{"/candles?interval=5m", "/candles?interval=2h", "/candles?currency=XYZ", "/candles?limit=0"} {
+		code, _ = get(t, srv.candlesHandler, target)
+		require.Equal(t, http.StatusBadRequest, code, target)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(srv.candlesWsHandler))
+	defer ts.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"?interval=1m&asset=BTC", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// the candle in progress comes first, then its updates
+	var update model.Candle
+	require.NoError(t, ws.ReadJSON(&update))
+	require.Equal(t, "100", update.Close.String())
+	priceHub.Publish(tick("ETH", 2000))
+	priceHub.Publish(tick("BTC", 120))
+	require.NoError(t, ws.ReadJSON(&update))
+	require.Equal(t, "BTC", update.Asset)
+	require.Equal(t, "120", update.High.String())
+	require.Equal(t, "100", update.Low.String())
+	require.False(t, update.Closed)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// Operations websocket clients may send, as {"id": "1", "op": "subscribe", "assets": ["ETH"]}.
+const (
+	// opSubscribe adds assets and currencies, "*" subscribes to all of them.
+	opSubscribe = "subscribe"
+	// opUnsubscribe removes assets and currencies, "*" unsubscribes from all of them.
+	opUnsubscribe = "unsubscribe"
+	// opReplay sends the stored prices after since_date or resume_from_seq, then live prices continue.
+	opReplay = "replay"
+	// opList returns the current subscription.
+	opList = "list"
+)
+
+// Error codes of command replies.
+const (
+	errBadRequest      = "bad_request"
+	errUnknownOp       = "unknown_op"
+	errUnknownCurrency = "unknown_currency"
+	errNotSubscribed   = "not_subscribed"
+	errReplayFailed    = "replay_failed"
+)
+
+// wildcard stands for every asset or currency.
+const wildcard = "*"
+
+// wsCommand is a frame sent by a websocket client.
+type wsCommand struct {
+	ID            string   `json:"id"`
+	Op            string   `json:"op"`
+	Assets        []string `json:"assets,omitempty"`
+	Currencies    []string `json:"currencies,omitempty"`
+	SinceDate     *int64   `json:"since_date,omitempty"`
+	ResumeFromSeq *uint64  `json:"resume_from_seq,omitempty"`
+
+	// err is set when the frame couldn't be decoded
+	err error
+}
+
+// wsReply acknowledges a command or tells why it failed, replies carry the ID of their command.
+type wsReply struct {
+	ID           string          `json:"id,omitempty"`
+	Type         string          `json:"type"`
+	Op           string          `json:"op,omitempty"`
+	Subscription *wsSubscription `json:"subscription,omitempty"`
+	Replayed     *int            `json:"replayed,omitempty"`
+	Error        *wsError        `json:"error,omitempty"`
+}
+
+// wsSubscription lists what a connection receives, "*" standing for every asset or currency.
+type wsSubscription struct {
+	Assets     []string `json:"assets"`
+	Currencies []string `json:"currencies"`
+}
+
+type wsError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// readCommands decodes the frames of the client until the connection fails.
+func readCommands(ctx context.Context, conn *websocket.Conn, commands chan<- wsCommand) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var command wsCommand
+		if err = json.Unmarshal(message, &command); err != nil {
+			command.err = err
+		}
+		select {
+		case commands <- command:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handleCommand runs command and replies to it, only failing to write the reply is returned.
+func (s *Server) handleCommand(ctx context.Context, conn *websocket.Conn, sub *hub.Subscription, command wsCommand, fields []stats.Field) error {
+	reply := wsReply{ID: command.ID, Type: "ack", Op: command.Op}
+	fail := func(code, format string, args ...interface{}) error {
+		return writeReply(conn, wsReply{ID: command.ID, Type: "error", Op: command.Op,
+			Error: &wsError{Code: code, Message: fmt.Sprintf(format, args...)}})
+	}
+	if command.err != nil {
+		return fail(errBadRequest, "malformed command: %v", command.err)
+	}
+
+	switch command.Op {
+	case opSubscribe, opUnsubscribe:
+		if unknown := model.UnknownCurrencies(difference(command.Currencies, []string{wildcard})); len(unknown) > 0 {
+			return fail(errUnknownCurrency, "unknown currency %s", strings.Join(unknown, ", "))
+		}
+		filter := sub.Filter()
+		if command.Op == opSubscribe {
+			filter = subscribe(filter, command.Assets, command.Currencies)
+		} else {
+			var err error
+			if filter, err = unsubscribe(filter, command.Assets, command.Currencies); err != nil {
+				return fail(errNotSubscribed, "%v", err)
+			}
+		}
+		sub.SetFilter(filter)
+		reply.Subscription = describe(filter)
+	case opList:
+		reply.Subscription = describe(sub.Filter())
+	case opReplay:
+		var missed []*model.CurrentPrice
+		var err error
+		switch {
+		case command.ResumeFromSeq != nil:
+			missed, err = s.hub.Resume(ctx, sub, *command.ResumeFromSeq)
+		case command.SinceDate != nil:
+			missed, err = s.hub.Replay(ctx, sub, time.Unix(*command.SinceDate, 10))
+		default:
+			return fail(errBadRequest, "replay needs since_date or resume_from_seq")
+		}
+		// live prices taken over by the replay are sent either way
+		if sendErr := s.sendPrices(conn, sub, missed, fields); sendErr != nil {
+			return sendErr
+		}
+		if err != nil {
+			log.Err(err).Msgf("something wrong with legacy data %v", err)
+			return fail(errReplayFailed, "stored prices are unavailable")
+		}
+		replayed := len(missed)
+		reply.Replayed = &replayed
+	default:
+		return fail(errUnknownOp, "unknown op %q", command.Op)
+	}
+	return writeReply(conn, reply)
+}
+
+func writeReply(conn *websocket.Conn, reply wsReply) error {
+	marshalled, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, marshalled)
+}
+
+// subscribe adds assets and currencies to filter.
+func subscribe(filter hub.Filter, assets, currencies []string) hub.Filter {
+	filter.Assets, filter.MatchNone = add(filter.Assets, filter.MatchNone, assets)
+	filter.Currencies, filter.MatchNoCurrency = add(filter.Currencies, filter.MatchNoCurrency, currencies)
+	return filter
+}
+
+// unsubscribe removes assets and currencies from filter, the subscription stays open when none is left. Single
+// values can't be removed while subscribed to every one of them.
+func unsubscribe(filter hub.Filter, assets, currencies []string) (hub.Filter, error) {
+	var err error
+	if filter.Assets, filter.MatchNone, err = remove(filter.Assets, filter.MatchNone, assets, "asset"); err != nil {
+		return filter, err
+	}
+	if filter.Currencies, filter.MatchNoCurrency, err = remove(filter.Currencies, filter.MatchNoCurrency, currencies, "currency"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// add adds values to list, none telling that list matches nothing. An empty list matches everything already.
+func add(list []string, none bool, values []string) ([]string, bool) {
+	switch {
+	case len(values) == 0:
+		return list, none
+	case containsFold(values, wildcard), !none && len(list) == 0:
+		return nil, false
+	}
+	return union(list, values), false
+}
+
+// remove removes values from list, none telling that list matches nothing.
+func remove(list []string, none bool, values []string, kind string) ([]string, bool, error) {
+	switch {
+	case len(values) == 0 || none:
+		return list, none, nil
+	case containsFold(values, wildcard):
+		return nil, true, nil
+	case len(list) == 0:
+		return list, none, fmt.Errorf("subscribed to every %s, unsubscribe from %q and subscribe to the ones to keep", kind, wildcard)
+	}
+	list = difference(list, values)
+	return list, len(list) == 0, nil
+}
+
+func describe(filter hub.Filter) *wsSubscription {
+	return &wsSubscription{
+		Assets:     describeList(filter.Assets, filter.MatchNone),
+		Currencies: describeList(filter.Currencies, filter.MatchNoCurrency),
+	}
+}
+
+func describeList(list []string, none bool) []string {
+	switch {
+	case none:
+		return []string{}
+	case len(list) == 0:
+		return []string{wildcard}
+	}
+	return append([]string{}, list...)
+}
+
+// union appends the values missing from list, upper-cased.
+func union(list, values []string) []string {
+	res := append([]string(nil), list...)
+	for _, value := range values {
+		value = strings.ToUpper(value)
+		if value != wildcard && !containsFold(res, value) {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+func difference(list, values []string) []string {
+	var res []string
+	for _, item := range list {
+		if !containsFold(values, item) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
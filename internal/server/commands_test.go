@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func command(t *testing.T, ws *websocket.Conn, frame string) map[string]interface{} {
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(frame)))
+	return readFrame(t, ws)
+}
+
+func readFrame(t *testing.T, ws *websocket.Conn) map[string]interface{} {
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	_, msg, err := ws.ReadMessage()
+	require.NoError(t, err)
+	var frame map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg, &frame))
+	return frame
+}
+
+func TestWebsocketCommands(t *testing.T) {
+	usd := func(asset string, seq uint64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: asset, Seq: seq, Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.NewDecimal(int64(seq), 0)},
+			"EUR": {Value: model.NewDecimal(int64(seq), 0)},
+		}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetSinceSeq", mock.Anything, uint64(7), []string{"btc", "ETH"}).
+		Return([]*model.CurrentPrice{usd("ETH", 8), usd("BTC", 9)}, nil).Once()
+	priceHub := hub.New(nil, nil, repo)
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(srv.wsHandler))
+	defer ts.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"?asset=btc", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	reply := command(t, ws, `{"id":"1","op":"list"}`)
+	require.Equal(t, map[string]interface{}{"id": "1", "type": "ack", "op": "list",
+		"subscription": map[string]interface{}{"assets": []interface{}{"btc"}, "currencies": []interface{}{"*"}}}, reply)
+
+	// a connection opened without currency keeps receiving every currency
+	reply = command(t, ws, `{"id":"2","op":"subscribe","assets":["eth"],"currencies":["eur"]}`)
+	require.Equal(t, "ack", reply["type"])
+	require.Equal(t, map[string]interface{}{"assets": []interface{}{"btc", "ETH"}, "currencies": []interface{}{"*"}},
+		reply["subscription"])
+
+	priceHub.Publish(usd("SOL", 1))
+	priceHub.Publish(usd("ETH", 2))
+	price := readFrame(t, ws)
+	require.Equal(t, "ETH", price["asset"])
+	require.Equal(t, float64(2), price["price"])
+	require.NotContains(t, price, "price_eur")
+
+	// single currencies are picked after unsubscribing from all of them
+	reply = command(t, ws, `{"id":"2a","op":"unsubscribe","currencies":["eur"]}`)
+	require.Equal(t, "not_subscribed", reply["error"].(map[string]interface{})["code"])
+	reply = command(t, ws, `{"id":"2b","op":"unsubscribe","currencies":["*"]}`)
+	require.Equal(t, []interface{}{}, reply["subscription"].(map[string]interface{})["currencies"])
+	priceHub.Publish(usd("ETH", 3))
+	reply = command(t, ws, `{"id":"2c","op":"subscribe","currencies":["eur"]}`)
+	require.Equal(t, "2c", reply["id"])
+	require.Equal(t, []interface{}{"EUR"}, reply["subscription"].(map[string]interface{})["currencies"])
+	priceHub.Publish(usd("ETH", 4))
+	require.Equal(t, float64(4), readFrame(t, ws)["price_eur"])
+
+	// replayed prices come before the ack
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"id":"3","op":"replay","resume_from_seq":7}`)))
+	require.Equal(t, float64(8), readFrame(t, ws)["seq"])
+	require.Equal(t, float64(9), readFrame(t, ws)["seq"])
+	reply = readFrame(t, ws)
+	require.Equal(t, "3", reply["id"])
+	require.Equal(t, float64(2), reply["replayed"])
+
+	reply = command(t, ws, `{"id":"4","op":"unsubscribe","assets":["*"]}`)
+	require.Equal(t, map[string]interface{}{"assets": []interface{}{}, "currencies": []interface{}{"EUR"}},
+		reply["subscription"])
+	priceHub.Publish(usd("ETH", 10))
+	require.Equal(t, "5", command(t, ws, `{"id":"5","op":"list"}`)["id"])
+
+	// unsubscribing from the last currency doesn't widen the subscription again
+	reply = command(t, ws, `{"id":"5a","op":"unsubscribe","currencies":["EUR"]}`)
+	require.Equal(t, map[string]interface{}{"assets": []interface{}{}, "currencies": []interface{}{}}, reply["subscription"])
+	reply = command(t, ws, `{"id":"5b","op":"subscribe","assets":["*"]}`)
+	require.Equal(t, map[string]interface{}{"assets": []interface{}{"*"}, "currencies": []interface{}{}}, reply["subscription"])
+	reply = command(t, ws, `{"id":"5c","op":"unsubscribe","assets":["btc"]}`)
+	require.Equal(t, "not_subscribed", reply["error"].(map[string]interface{})["code"])
+
+	reply = command(t, ws, `{"id":"6","op":"subscribe","currencies":["XYZ"]}`)
+	require.Equal(t, "error", reply["type"])
+	require.Equal(t, "unknown_currency", reply["error"].(map[string]interface{})["code"])
+
+	reply = command(t, ws, `{"id":"7","op":"replay"}`)
+	require.Equal(t, "bad_request", reply["error"].(map[string]interface{})["code"])
+
+	reply = command(t, ws, `{"id":"8","op":"publish"}`)
+	require.Equal(t, "unknown_op", reply["error"].(map[string]interface{})["code"])
+
+	reply = command(t, ws, `not json`)
+	require.Equal(t, "error", reply["type"])
+	require.Equal(t, "bad_request", reply["error"].(map[string]interface{})["code"])
+
+	// the handler is done once the client left, so it doesn't outlive the test
+	ws.Close()
+	require.Eventually(t, func() bool { return priceHub.Count() == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/candle"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultCandleLimit = 500
+	maxCandleLimit     = 1000
+)
+
+// WithCandles serves the candles built by aggregator on GetCandles.
+func (s *PricesServer) WithCandles(aggregator *candle.Aggregator) *PricesServer {
+	s.candles = aggregator
+	return s
+}
+
+func (s *PricesServer) GetCandles(ctx context.Context, req *pb.CandlesRequest) (*pb.CandlesResponse, error) {
+	if s.candles == nil {
+		return nil, status.Errorf(codes.Unimplemented, "candles are disabled")
+	}
+	query := repository.CandleQuery{
+		Asset:    strings.ToUpper(req.GetAsset()),
+		Currency: strings.ToUpper(req.GetCurrency()),
+		Limit:    int(req.GetLimit()),
+	}
+	if query.Asset == "" {
+		query.Asset = model.DefaultAsset
+	}
+	if query.Currency == "" {
+		query.Currency = "USD"
+	}
+	if unknown := model.UnknownCurrencies([]string{query.Currency}); len(unknown) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown currency %s", query.Currency)
+	}
+	interval, err := s.candles.Interval(req.GetInterval())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	query.Interval = string(interval)
+	if query.Limit == 0 {
+		query.Limit = defaultCandleLimit
+	}
+	if query.Limit < 0 || query.Limit > maxCandleLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxCandleLimit)
+	}
+	if req.GetFrom() != 0 {
+		query.From = time.Unix(req.GetFrom(), 0)
+	}
+	if req.GetTo() != 0 {
+		query.To = time.Unix(req.GetTo(), 0)
+	}
+
+	candles, err := s.candles.Candles(ctx, query)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := &pb.CandlesResponse{Asset: query.Asset, Currency: query.Currency, Interval: query.Interval}
+	for _, c := range candles {
+		res.Candles = append(res.Candles, &pb.Candle{
+			Start:  c.Start.Unix(),
+			Open:   c.Open.String(),
+			High:   c.High.String(),
+			Low:    c.Low.String(),
+			Close:  c.Close.String(),
+			Closed: c.Closed,
+		})
+	}
+	return res, nil
+}
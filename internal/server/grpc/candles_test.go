@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/candle"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetCandles(t *testing.T) {
+	start := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	repo := mockRepo.NewMockCandles(t)
+	repo.On("GetRange", mock.Anything, repository.CandleQuery{Asset: "ETH", Currency: "EUR", Interval: "1h", From: time.Unix(start.Unix(), 0), Limit: 500}).
+		Return([]*model.Candle{{Asset: "ETH", Currency: "EUR", Interval: "1h", Start: start,
+			Open: model.NewDecimal(1, 0), High: model.NewDecimal(3, 0), Low: model.NewDecimal(1, 0), Close: model.NewDecimal(2, 0)}}, nil).Once()
+
+	s := NewPricesServer(nil, nil)
+	_, err := s.GetCandles(context.Background(), &pb.CandlesRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	s.WithCandles(candle.NewAggregator(repo, []candle.Interval{candle.Minute, candle.Hour}))
+	res, err := s.GetCandles(context.Background(), &pb.CandlesRequest{Asset: "eth", Currency: "eur", Interval: "1h", From: start.Unix()})
+	require.NoError(t, err)
+	require.Equal(t, "1h", res.Interval)
+	require.Len(t, res.Candles, 1)
+	require.Equal(t, &pb.Candle{Start: start.Unix(), Open: "1", High: "3", Low: "1", Close: "2", Closed: true}, res.Candles[0])
+
+	for _, req := range []*pb.CandlesRequest{{Interval: "1d"}, {Currency: "XYZ"}, {Limit: 5000}} {
+		_, err = s.GetCandles(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+}
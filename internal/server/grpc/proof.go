@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"code.injective.org/service/pricefetcher/internal/audit"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithProver serves inclusion proofs of sealed prices on GetPriceProof.
+func (s *PricesServer) WithProver(prover *audit.Prover) *PricesServer {
+	s.prover = prover
+	return s
+}
+
+func (s *PricesServer) GetPriceProof(ctx context.Context, req *pb.ProofRequest) (*pb.ProofResponse, error) {
+	if s.prover == nil {
+		return nil, status.Errorf(codes.Unimplemented, "price batches are disabled")
+	}
+	if req.GetSeq() == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "seq is required")
+	}
+
+	proof, err := s.prover.Proof(ctx, req.GetSeq())
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "no price with seq %d", req.GetSeq())
+	case errors.Is(err, audit.ErrNotSealed):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &pb.ProofResponse{
+		Batch:     proof.Batch,
+		Root:      proof.Root,
+		SealedAt:  proof.SealedAt,
+		LeafIndex: int32(proof.LeafIndex),
+		LeafCount: int32(proof.LeafCount),
+		Asset:     proof.Leaf.Asset,
+		Timestamp: proof.Leaf.Timestamp,
+		Round:     proof.Leaf.Round,
+		Prices:    proof.Leaf.Prices,
+	}
+	for _, step := range proof.Path {
+		res.Path = append(res.Path, &pb.ProofStep{Hash: step.Hash, Left: step.Left})
+	}
+	return res, nil
+}
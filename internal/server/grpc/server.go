@@ -1,107 +1,162 @@
 package grpc
 
 import (
-	"context"
-	"fmt"
 	"strings"
-	"sync"
 	"time"
 
+	"code.injective.org/service/pricefetcher/internal/audit"
+	"code.injective.org/service/pricefetcher/internal/candle"
 	"code.injective.org/service/pricefetcher/internal/config"
+	"code.injective.org/service/pricefetcher/internal/hub"
 	"code.injective.org/service/pricefetcher/internal/model"
-	"code.injective.org/service/pricefetcher/internal/repository"
+	"code.injective.org/service/pricefetcher/internal/stats"
 	pb "code.injective.org/service/pricefetcher/proto/prices"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-const queueBufferSize = 100
-
 type PricesServer struct {
 	pb.UnimplementedPricesStreamingServiceServer
 
-	receiverCh chan *model.CurrentPrice
-	errorCh    chan error
-	cfg        *config.Config
-	repo       repository.Prices
-	mutex      sync.Mutex
-	multi      map[string]chan *model.CurrentPrice
+	hub     *hub.Hub
+	cfg     *config.Config
+	prover  *audit.Prover
+	candles *candle.Aggregator
+	stats   *stats.Service
 }
 
-func NewPricesServer(
-	receiverCh chan *model.CurrentPrice,
-	errorCh chan error,
-	cfg *config.Config,
-	repo repository.Prices) *PricesServer {
-	multi := make(map[string]chan *model.CurrentPrice)
-
-	return &PricesServer{receiverCh: receiverCh, errorCh: errorCh, cfg: cfg, repo: repo, multi: multi}
+func NewPricesServer(h *hub.Hub, cfg *config.Config) *PricesServer {
+	return &PricesServer{hub: h, cfg: cfg}
 }
 
-func (s PricesServer) GetDataStreaming(req *pb.PricesRequest, srv pb.PricesStreamingService_GetDataStreamingServer) error {
-	// buffered channel to keep prices in queue
-	queueMsg := make(chan *model.CurrentPrice, queueBufferSize)
-	defer func() {
-		s.mutex.Lock()
-		delete(s.multi, uuid.NewString())
-		s.mutex.Unlock()
-		close(queueMsg)
-	}()
+func (s *PricesServer) GetDataStreaming(req *pb.PricesRequest, srv pb.PricesStreamingService_GetDataStreamingServer) error {
+	if unknown := model.UnknownCurrencies(req.GetCurrency()); len(unknown) > 0 {
+		return status.Errorf(codes.InvalidArgument, "unknown currency %s", strings.Join(unknown, ", "))
+	}
 
-	s.mutex.Lock()
-	s.multi[uuid.NewString()] = queueMsg
-	s.mutex.Unlock()
+	policy, err := hub.ParsePolicy(req.GetPolicy())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var fields []stats.Field
+	if len(req.GetStats()) > 0 {
+		if s.stats == nil {
+			return status.Error(codes.InvalidArgument, "price statistics are disabled")
+		}
+		if fields, err = stats.ParseFields(req.GetStats()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
 
 	currency := req.GetCurrency()
-	if req.GetSinceDate() != 0 {
-		legacy, err := s.repo.GetSinceDate(context.Background(), time.Unix(int64(req.GetSinceDate()), 10))
+	filter := hub.Filter{Assets: req.GetAsset(), Currencies: currency}
+
+	// subscribe before replaying, the hub buffers what is published meanwhile and hands off to live updates
+	sub := s.hub.Subscribe(srv.Context(), filter, policy)
+	defer sub.Close()
+
+	if req.ResumeFromSeq != nil {
+		missed, err := s.hub.Resume(srv.Context(), sub, req.GetResumeFromSeq())
+		if err != nil {
+			log.Err(err).Msgf("something wrong with legacy data %v", err)
+		}
+		for _, rate := range missed {
+			if err = s.sendReceivedPrice(srv, rate, currency, fields); err != nil {
+				log.Err(err).Msgf("something wrong with connections %v", err)
+				return err
+			}
+		}
+	} else if req.GetSinceDate() != 0 {
+		legacy, err := s.hub.Replay(srv.Context(), sub, time.Unix(int64(req.GetSinceDate()), 10))
 		if err != nil {
 			log.Err(err).Msgf("something wrong with legacy data %v", err)
 		}
 		for _, rate := range legacy {
-			err = s.sendReceivedPrice(srv, rate, currency)
-			if err != nil {
+			if err = s.sendReceivedPrice(srv, rate, currency, fields); err != nil {
 				log.Err(err).Msgf("something wrong with connections %v", err)
+				return err
 			}
 		}
 	}
 
-	for {
-		select {
-		case rate := <-queueMsg:
-			log.Info().Msgf("received rate %v", rate)
-			err := s.sendReceivedPrice(srv, rate, currency)
-			if err != nil {
-				log.Err(err).Msgf("error sending pricing data")
-			}
-		case errMsg := <-s.errorCh:
-			if errMsg != nil {
-				log.Err(errMsg).Msgf("something goes wrong with channel %s", errMsg)
-			}
+	// the subscription is closed when the client goes away
+	for rate := range sub.C() {
+		if err := s.sendReceivedPrice(srv, rate, currency, fields); err != nil {
+			log.Err(err).Msgf("error sending pricing data")
+			return err
 		}
 	}
-
+	if err = sub.Err(); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
 	return nil
 }
 
-func (s PricesServer) sendReceivedPrice(conn pb.PricesStreamingService_GetDataStreamingServer,
-	rate *model.CurrentPrice, currency []string) error {
+func (s *PricesServer) sendReceivedPrice(conn pb.PricesStreamingService_GetDataStreamingServer,
+	rate *model.CurrentPrice, currency []string, fields []stats.Field) error {
 	res := &pb.PricesResponse{
 		TimeDate: rate.Time.UpdatedISO.Unix(),
-		Price:    fmt.Sprintf("%f", rate.Bpi.Usd.RateFloat),
+		Asset:    rate.Asset,
+		Round:    rate.Round,
+		Seq:      rate.Seq,
+	}
+	if usd, ok := rate.Rate("USD"); ok {
+		res.Price = usd.Value.String()
+		if signature, ok := rate.Signatures["USD"]; ok {
+			res.Signatures = map[string]string{"USD": signature}
+		}
 	}
 
+	seen := make(map[string]bool)
 	for _, cur := range currency {
-		if strings.EqualFold(cur, "usd") {
-			res.PriceUsd = fmt.Sprintf("%f", rate.Bpi.Usd.RateFloat)
+		code := strings.ToUpper(cur)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		quote, ok := rate.Rate(code)
+		if !ok {
+			continue
+		}
+		if res.Prices == nil {
+			res.Prices = make(map[string]string)
+		}
+		price := quote.Value.String()
+		res.Prices[code] = price
+		if quote.Derived {
+			res.Derived = append(res.Derived, code)
+		}
+		if signature, ok := rate.Signatures[code]; ok {
+			if res.Signatures == nil {
+				res.Signatures = make(map[string]string)
+			}
+			res.Signatures[code] = signature
+		}
+
+		// legacy fields are kept for clients built before the prices map
+		if code == "USD" {
+			res.PriceUsd = price
 		}
 
-		if strings.EqualFold(cur, "eur") {
-			res.PriceEur = fmt.Sprintf("%f", rate.Bpi.Eur.RateFloat)
+		if code == "EUR" {
+			res.PriceEur = price
 		}
 
-		if strings.EqualFold(cur, "gbp") {
-			res.PriceGbp = fmt.Sprintf("%f", rate.Bpi.Gbp.RateFloat)
+		if code == "GBP" {
+			res.PriceGbp = price
+		}
+	}
+	if len(res.Signatures) > 0 {
+		res.KeyId = rate.KeyID
+	}
+	if s.stats != nil {
+		for _, value := range s.stats.Values(rate, "USD", fields) {
+			if res.Stats == nil {
+				res.Stats = make(map[string]string)
+			}
+			res.Stats[value.Name] = value.Value.String()
 		}
 	}
 	return conn.Send(res)
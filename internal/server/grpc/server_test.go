@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, ctx context.Context, priceHub *hub.Hub) pb.PricesStreamingServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterPricesStreamingServiceServer(s, NewPricesServer(priceHub, nil))
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPricesStreamingServiceClient(conn)
+}
+
+func TestStreamingReceivesLiveUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	receiver := make(chan *model.CurrentPrice)
+	priceHub := hub.New(receiver, make(chan error), nil)
+	go priceHub.Run(ctx)
+	client := newTestClient(t, ctx, priceHub)
+
+	// a currency requested twice is sent once
+	stream, err := client.GetDataStreaming(ctx, &pb.PricesRequest{Currency: []string{"usd", "jpy", "JPY"}, Asset: []string{"ETH"}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return priceHub.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	btc := &model.CurrentPrice{Asset: "BTC", Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(40000, 0)}}}
+	eth := &model.CurrentPrice{
+		Asset: "ETH",
+		Time:  model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0)},
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.MustParseDecimal("2000.123456789")},
+			"JPY": {Value: model.NewDecimal(300000, 0), Derived: true},
+		},
+	}
+	receiver <- btc
+	receiver <- eth
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "ETH", res.Asset)
+	require.Equal(t, int64(1706015736), res.TimeDate)
+	require.Equal(t, "2000.123456789", res.Price)
+	require.Equal(t, map[string]string{"USD": "2000.123456789", "JPY": "300000"}, res.Prices)
+	require.Equal(t, []string{"JPY"}, res.Derived)
+
+	// an unknown currency is rejected when the stream is read
+	bad, err := client.GetDataStreaming(ctx, &pb.PricesRequest{Currency: []string{"XYZ"}})
+	require.NoError(t, err)
+	_, err = bad.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// closing the stream unsubscribes
+	cancel()
+	require.Eventually(t, func() bool { return priceHub.Count() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamingResumesFromSeq(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	usd := func(seq uint64) *model.CurrentPrice {
+		return &model.CurrentPrice{Seq: seq, Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(int64(seq), 0)}}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetSinceSeq", mock.Anything, uint64(10), []string(nil)).
+		Return([]*model.CurrentPrice{usd(11), usd(12)}, nil).Once()
+	receiver := make(chan *model.CurrentPrice)
+	priceHub := hub.New(receiver, make(chan error), repo)
+	go priceHub.Run(ctx)
+	client := newTestClient(t, ctx, priceHub)
+
+	resumeFrom := uint64(10)
+	stream, err := client.GetDataStreaming(ctx, &pb.PricesRequest{ResumeFromSeq: &resumeFrom})
+	require.NoError(t, err)
+	for _, seq := range []uint64{11, 12} {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, seq, res.Seq)
+	}
+
+	// the replayed update published live again is not sent twice
+	receiver <- usd(12)
+	receiver <- usd(13)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), res.Seq)
+	require.Equal(t, "13", res.Price)
+}
+
+func TestStreamChurn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	priceHub := hub.New(make(chan *model.CurrentPrice), make(chan error), nil)
+	go priceHub.Run(ctx)
+	client := newTestClient(t, ctx, priceHub)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			streamCtx, streamCancel := context.WithCancel(ctx)
+			defer streamCancel()
+			if _, err := client.GetDataStreaming(streamCtx, &pb.PricesRequest{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return priceHub.Count() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// sentPrices records what a stream sends.
+type sentPrices struct {
+	pb.PricesStreamingService_GetDataStreamingServer
+	sent []*pb.PricesResponse
+}
+
+func (s *sentPrices) Send(res *pb.PricesResponse) error {
+	s.sent = append(s.sent, res)
+	return nil
+}
+
+func TestStreamingSignsUSDPrice(t *testing.T) {
+	rate := &model.CurrentPrice{
+		Asset: "BTC",
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.NewDecimal(40000, 0)},
+			"EUR": {Value: model.NewDecimal(37000, 0)},
+		},
+		Signatures: map[string]string{"USD": "usd-signature", "EUR": "eur-signature"},
+	}
+	conn := &sentPrices{}
+	s := NewPricesServer(nil, nil)
+
+	// the USD price is sent with its signature whatever currencies were requested
+	require.NoError(t, s.sendReceivedPrice(conn, rate, []string{"eur"}, nil))
+	require.NoError(t, s.sendReceivedPrice(conn, rate, nil, nil))
+	require.Equal(t, map[string]string{"USD": "usd-signature", "EUR": "eur-signature"}, conn.sent[0].Signatures)
+	require.Equal(t, map[string]string{"USD": "usd-signature"}, conn.sent[1].Signatures)
+}
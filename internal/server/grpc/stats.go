@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultStatsWindow = 24 * time.Hour
+	maxStatsRange      = 31 * 24 * time.Hour
+)
+
+// WithStats serves price statistics on GetPriceStats and lets stream subscribers opt into statistics fields.
+func (s *PricesServer) WithStats(service *stats.Service) *PricesServer {
+	s.stats = service
+	return s
+}
+
+func (s *PricesServer) GetPriceStats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	if s.stats == nil {
+		return nil, status.Errorf(codes.Unimplemented, "price statistics are disabled")
+	}
+	asset, currency := strings.ToUpper(req.GetAsset()), strings.ToUpper(req.GetCurrency())
+	if asset == "" {
+		asset = model.DefaultAsset
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+	if unknown := model.UnknownCurrencies([]string{currency}); len(unknown) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown currency %s", currency)
+	}
+
+	to := time.Now()
+	if req.GetTo() != 0 {
+		to = time.Unix(req.GetTo(), 0)
+	}
+	from := time.Unix(req.GetFrom(), 0)
+	if req.GetFrom() == 0 {
+		window := defaultStatsWindow
+		if req.GetWindow() != "" {
+			var err error
+			if window, err = stats.ParseWindow(req.GetWindow()); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+		from = to.Add(-window)
+	}
+	if !from.Before(to) || to.Sub(from) > maxStatsRange {
+		return nil, status.Errorf(codes.InvalidArgument, "from must be before to and at most %s earlier", maxStatsRange)
+	}
+
+	res, err := s.stats.Query(ctx, asset, currency, from, to)
+	if errors.Is(err, stats.ErrNoPrices) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.StatsResponse{
+		Asset:    res.Asset,
+		Currency: res.Currency,
+		From:     res.From.Unix(),
+		To:       res.To.Unix(),
+		Ticks:    int32(res.Ticks),
+		Last:     res.Last.String(),
+		Twap:     res.TWAP.String(),
+		Min:      res.Min.String(),
+		Max:      res.Max.String(),
+		Stddev:   res.StdDev.String(),
+		Change:   res.Change.String(),
+	}, nil
+}
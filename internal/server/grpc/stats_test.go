@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	pb "code.injective.org/service/pricefetcher/proto/prices"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetPriceStats(t *testing.T) {
+	to := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-time.Hour)
+	price := func(at time.Time, eur int64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: "ETH", Time: model.CurrentPriceTime{UpdatedISO: at},
+			Bpi: map[string]model.CurrentPriceRate{"EUR": {Value: model.NewDecimal(eur, 0)}}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: from, To: to, Assets: []string{"ETH"}, Limit: 1000}).
+		Return([]*model.CurrentPrice{price(from, 100), price(from.Add(30*time.Minute), 200)}, "", nil).Once()
+
+	s := NewPricesServer(nil, nil)
+	_, err := s.GetPriceStats(context.Background(), &pb.StatsRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	s.WithStats(stats.NewService(repo, nil))
+	res, err := s.GetPriceStats(context.Background(), &pb.StatsRequest{Asset: "eth", Currency: "eur", To: to.Unix(), Window: "1h"})
+	require.NoError(t, err)
+	require.Equal(t, &pb.StatsResponse{Asset: "ETH", Currency: "EUR", From: from.Unix(), To: to.Unix(), Ticks: 2,
+		Last: "200", Twap: "150", Min: "100", Max: "200", Stddev: "50", Change: "100"}, res)
+
+	for _, req := range []*pb.StatsRequest{{Window: "1x"}, {Currency: "XYZ"}, {Window: "90d"}, {From: to.Unix(), To: from.Unix()}} {
+		_, err = s.GetPriceStats(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+}
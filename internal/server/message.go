@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
+)
+
+// PriceQuote is a price in a single quote currency.
+type PriceQuote struct {
+	Currency  string
+	Price     model.Decimal
+	Derived   bool
+	Signature string
+}
+
+// PriceMsg is the frame sent to websocket clients. Requested quotes are flattened
+// into `price_<currency>` fields following the order they were requested in, currencies
+// computed from FX reference rates are listed in `derived`. `seq` orders stored prices and is
+// what clients resume from with `resume_from_seq`. `round` is only set when
+// prices are published under the deviation/heartbeat policy. Signed prices list the
+// attestation of every quote in `signatures`, see the attestation package. Statistics clients opted into
+// with `stats` follow the quotes as fields named after them, e.g. `twap_1h`, computed over the USD price.
+type PriceMsg struct {
+	TimeDate time.Time
+	Asset    string
+	Seq      uint64
+	Round    uint64
+	Price    model.Decimal
+	Quotes   []PriceQuote
+	// PriceSignature signs Price, it is sent whenever Price is.
+	PriceSignature string
+	KeyID          string
+	Stats          []stats.Value
+}
+
+func newPriceMsg(rate *model.CurrentPrice, currency []string) PriceMsg {
+	message := PriceMsg{
+		TimeDate: rate.Time.UpdatedISO,
+		Asset:    rate.Asset,
+		Seq:      rate.Seq,
+		Round:    rate.Round,
+		KeyID:    rate.KeyID,
+	}
+	if usd, ok := rate.Rate("USD"); ok {
+		message.Price = usd.Value
+		message.PriceSignature = rate.Signatures["USD"]
+	}
+
+	seen := make(map[string]bool)
+	for _, cur := range currency {
+		code := strings.ToUpper(cur)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		if quote, ok := rate.Rate(code); ok {
+			message.Quotes = append(message.Quotes, PriceQuote{
+				Currency:  code,
+				Price:     quote.Value,
+				Derived:   quote.Derived,
+				Signature: rate.Signatures[code],
+			})
+		}
+	}
+	return message
+}
+
+func (m PriceMsg) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if err := writeField(&buf, "timedate", m.TimeDate); err != nil {
+		return nil, err
+	}
+	if m.Asset != "" {
+		if err := writeField(&buf, "asset", m.Asset); err != nil {
+			return nil, err
+		}
+	}
+	if m.Seq != 0 {
+		if err := writeField(&buf, "seq", m.Seq); err != nil {
+			return nil, err
+		}
+	}
+	if m.Round != 0 {
+		if err := writeField(&buf, "round", m.Round); err != nil {
+			return nil, err
+		}
+	}
+	if !m.Price.IsZero() {
+		if err := writeField(&buf, "price", m.Price); err != nil {
+			return nil, err
+		}
+	}
+	var derived []string
+	signatures := make(map[string]string)
+	for _, quote := range m.Quotes {
+		if quote.Price.IsZero() {
+			continue
+		}
+		if err := writeField(&buf, "price_"+strings.ToLower(quote.Currency), quote.Price); err != nil {
+			return nil, err
+		}
+		if quote.Derived {
+			derived = append(derived, quote.Currency)
+		}
+		if quote.Signature != "" {
+			signatures[quote.Currency] = quote.Signature
+		}
+	}
+	for _, value := range m.Stats {
+		if err := writeField(&buf, value.Name, value.Value); err != nil {
+			return nil, err
+		}
+	}
+	if !m.Price.IsZero() && m.PriceSignature != "" {
+		signatures["USD"] = m.PriceSignature
+	}
+	if len(derived) > 0 {
+		if err := writeField(&buf, "derived", derived); err != nil {
+			return nil, err
+		}
+	}
+	if len(signatures) > 0 {
+		if err := writeField(&buf, "signatures", signatures); err != nil {
+			return nil, err
+		}
+		if err := writeField(&buf, "key_id", m.KeyID); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeField(buf *bytes.Buffer, name string, value interface{}) error {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(encoded)
+	return nil
+}
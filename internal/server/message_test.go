@@ -0,0 +1,85 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/attestation"
+	"code.injective.org/service/pricefetcher/internal/attest"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceMsgMarshal(t *testing.T) {
+	price := &model.CurrentPrice{
+		Asset: "ETH",
+		Round: 7,
+		Time:  model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0).UTC()},
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.MustParseDecimal("2000")},
+			"JPY": {Value: model.MustParseDecimal("300000"), Derived: true},
+		},
+	}
+
+	msg, err := json.Marshal(newPriceMsg(price, []string{"jpy", "USD", "JPY", "GBP"}))
+	require.NoError(t, err)
+	require.Equal(t, `{"timedate":"2024-01-23T13:15:36Z","asset":"ETH","round":7,"price":2000,"price_jpy":300000,"price_usd":2000,"derived":["JPY"]}`, string(msg))
+}
+
+func TestSignedPriceMsg(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	attester := attest.NewAttester(attestation.NewSigner(key))
+	price := attester.Apply(&model.CurrentPrice{
+		Time:  model.CurrentPriceTime{UpdatedISO: time.Unix(1706015736, 0).UTC()},
+		Round: 3,
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.MustParseDecimal("41234.123456789012345")},
+			"EUR": {Value: model.MustParseDecimal("37800.5")},
+		},
+	})
+
+	msg, err := json.Marshal(newPriceMsg(price, []string{"EUR", "USD"}))
+	require.NoError(t, err)
+	verified, err := attestation.VerifyFrame(key.Public().(ed25519.PublicKey), msg)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"EUR", "USD"}, verified)
+
+	// the USD price is signed when other currencies were requested
+	msg, err = json.Marshal(newPriceMsg(price, []string{"EUR"}))
+	require.NoError(t, err)
+	verified, err = attestation.VerifyFrame(key.Public().(ed25519.PublicKey), msg)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"EUR", "USD"}, verified)
+
+	// frames without currencies only sign the USD price
+	msg, err = json.Marshal(newPriceMsg(price, nil))
+	require.NoError(t, err)
+	verified, err = attestation.VerifyFrame(key.Public().(ed25519.PublicKey), msg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"USD"}, verified)
+
+	tampered := strings.Replace(string(msg), "41234.123456789012345", "41234.123456789012346", 1)
+	_, err = attestation.VerifyFrame(key.Public().(ed25519.PublicKey), []byte(tampered))
+	require.ErrorIs(t, err, attestation.ErrInvalidSignature)
+}
+
+func TestPubKeyHandler(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	s := Server{}
+	s.WithAttester(attest.NewAttester(attestation.NewSigner(key)))
+
+	rec := httptest.NewRecorder()
+	s.pubKeyHandler(rec, httptest.NewRequest(http.MethodGet, "/pubkey", nil))
+
+	var res map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	public, err := attestation.ParsePublicKey([]byte(res["public_key"]))
+	require.NoError(t, err)
+	require.Equal(t, key.Public(), public)
+	require.Equal(t, attestation.KeyID(public), res["key_id"])
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultStatsWindow = 24 * time.Hour
+	// maxStatsRange bounds how much history a single query reads back from storage
+	maxStatsRange = 31 * 24 * time.Hour
+)
+
+// WithStats serves price statistics on /prices/stats and lets stream subscribers opt into statistics fields.
+func (s *Server) WithStats(service *stats.Service) {
+	s.stats = service
+}
+
+// statsFields reads the statistics fields a stream subscriber opted into with stats=twap_1h,change_24h.
+func (s *Server) statsFields(queryMap url.Values) ([]stats.Field, error) {
+	if !queryMap.Has("stats") {
+		return nil, nil
+	}
+	if s.stats == nil {
+		return nil, errors.New("price statistics are disabled")
+	}
+	return stats.ParseFields(queryMap["stats"])
+}
+
+// priceMsg formats rate for the given currencies, with the statistics fields of its USD price.
+func (s *Server) priceMsg(rate *model.CurrentPrice, currency []string, fields []stats.Field) PriceMsg {
+	message := newPriceMsg(rate, currency)
+	if s.stats != nil {
+		message.Stats = s.stats.Values(rate, "USD", fields)
+	}
+	return message
+}
+
+// priceStatsHandler serves /prices/stats?asset=BTC&currency=USD&window=24h, the statistics of the window up
+// to now. Inclusive unix time bounds from and to may be given instead of the window, to defaults to now. The
+// asset defaults to BTC, the currency to USD and the window to 24h.
+func (s *Server) priceStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	queryMap := r.URL.Query()
+	asset, currency := queryMap.Get("asset"), queryMap.Get("currency")
+	if asset == "" {
+		asset = model.DefaultAsset
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+	if unknown := model.UnknownCurrencies([]string{currency}); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	from, err := queryTime(queryMap, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := queryTime(queryMap, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		window := defaultStatsWindow
+		if queryMap.Has("window") {
+			if window, err = stats.ParseWindow(queryMap.Get("window")); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		from = to.Add(-window)
+	}
+	if !from.Before(to) || to.Sub(from) > maxStatsRange {
+		http.Error(w, fmt.Sprintf("from must be before to and at most %s earlier", maxStatsRange), http.StatusBadRequest)
+		return
+	}
+
+	res, err := s.stats.Query(r.Context(), asset, currency, from, to)
+	if errors.Is(err, stats.ErrNoPrices) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Err(err).Msg("error computing price statistics")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		log.Err(err).Msg("error writing price statistics")
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceStats(t *testing.T) {
+	price := func(at time.Time, usd int64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: "BTC", Time: model.CurrentPriceTime{UpdatedISO: at},
+			Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(usd, 0)}}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	priceHub := hub.New(nil, nil, repo)
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+
+	// streaming statistics needs the service
+	code, _ := get(t, srv.sseHandler, "/sse?stats=twap_1h")
+	require.Equal(t, http.StatusBadRequest, code)
+	service := stats.NewService(repo, nil)
+	srv.WithStats(service)
+
+	to := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: to.Add(-2 * time.Hour), To: to, Assets: []string{"BTC"}, Limit: 1000}).
+		Return([]*model.CurrentPrice{price(to.Add(-2*time.Hour), 100), price(to.Add(-time.Hour), 300)}, "", nil).Once()
+	code, body := get(t, srv.priceStatsHandler, "/prices/stats?window=2h&to=1706011200")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]interface{}{
+		"asset": "BTC", "currency": "USD", "from": "2024-01-23T10:00:00Z", "to": "2024-01-23T12:00:00Z", "ticks": float64(2),
+		"last": float64(300), "twap": float64(200), "min": float64(100), "max": float64(300), "stddev": float64(100), "change": float64(200),
+	}, body)
+	for _, target := range []string{"/prices/stats?window=1x", "/prices/stats?currency=XYZ", "/prices/stats?from=20&to=10", "/prices/stats?window=90d"} {
+		code, _ = get(t, srv.priceStatsHandler, target)
+		require.Equal(t, http.StatusBadRequest, code, target)
+	}
+	repo.On("GetRange", mock.Anything, mock.Anything).Return(nil, "", nil).Once()
+	code, _ = get(t, srv.priceStatsHandler, "/prices/stats?asset=ETH&from=10&to=20")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// subscribers opt into statistics over the window up to every price
+	ts := httptest.NewServer(http.HandlerFunc(srv.sseHandler))
+	defer ts.Close()
+	code, _ = get(t, srv.sseHandler, "/sse?stats=avg_1h")
+	require.Equal(t, http.StatusBadRequest, code)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?stats=change_1h&stats=max_1h,twap_1h", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Eventually(t, func() bool { return priceHub.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	for _, p := range []*model.CurrentPrice{price(to, 100), price(to.Add(30*time.Minute), 150)} {
+		service.Add(p)
+		priceHub.Publish(p)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	require.Equal(t, []string{`data: {"timedate":"2024-01-23T12:00:00Z","asset":"BTC","price":100,"change_1h":0,"max_1h":100,"twap_1h":100}`},
+		readEvent(t, lines))
+	require.Equal(t, []string{`data: {"timedate":"2024-01-23T12:30:00Z","asset":"BTC","price":150,"change_1h":50,"max_1h":150,"twap_1h":100}`},
+		readEvent(t, lines))
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"code.injective.org/service/pricefetcher/internal/audit"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// WithProver serves inclusion proofs of sealed prices on /proof.
+func (s *Server) WithProver(prover *audit.Prover) {
+	s.prover = prover
+}
+
+// proofHandler serves /proof?seq=1042, seq being the one the price was streamed with.
+func (s *Server) proofHandler(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.ParseUint(r.URL.Query().Get("seq"), 10, 64)
+	if err != nil {
+		http.Error(w, "seq is required", http.StatusBadRequest)
+		return
+	}
+
+	proof, err := s.prover.Proof(r.Context(), seq)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		http.Error(w, "price not found", http.StatusNotFound)
+		return
+	case errors.Is(err, audit.ErrNotSealed):
+		http.Error(w, err.Error(), http.StatusTooEarly)
+		return
+	case err != nil:
+		log.Err(err).Msg("error building price proof")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(proof); err != nil {
+		log.Err(err).Msg("error writing price proof")
+	}
+}
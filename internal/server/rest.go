@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// WithPrices serves stored prices on /prices.
+func (s *Server) WithPrices(prices repository.Prices) {
+	s.prices = prices
+}
+
+// pricesPage is the body of every /prices endpoint, prices are formatted like websocket frames.
+type pricesPage struct {
+	Prices []PriceMsg `json:"prices"`
+	// NextCursor is passed as cursor to read the next page, it is omitted on the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pricesHandler serves /prices?from=1705938898&to=1706015736&asset=BTC&currency=EUR&limit=100&cursor=...,
+// the bounds are inclusive unix times and either may be omitted.
+func (s *Server) pricesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	queryMap := r.URL.Query()
+	currency, assets := queryFilter(queryMap)
+	if unknown := model.UnknownCurrencies(currency); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	query := repository.PriceQuery{
+		Assets:     assets,
+		Currencies: s.hub.StoredCurrencies(currency),
+		Limit:      defaultPageSize,
+		Cursor:     queryMap.Get("cursor"),
+	}
+	var err error
+	if query.From, err = queryTime(queryMap, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.To, err = queryTime(queryMap, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if queryMap.Has("limit") {
+		query.Limit, err = strconv.Atoi(queryMap.Get("limit"))
+		if err != nil || query.Limit < 1 || query.Limit > maxPageSize {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stored, next, err := s.prices.GetRange(r.Context(), query)
+	switch {
+	case errors.Is(err, repository.ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Err(err).Msg("error reading prices")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	s.writePrices(w, stored, currency, next)
+}
+
+// priceHandler serves /prices/latest, the last price of every asset, and /prices/1706015736, the prices stored
+// at a unix time. Both take the asset and currency parameters, the prices stored at a time are paged by cursor.
+func (s *Server) priceHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	queryMap := r.URL.Query()
+	currency, assets := queryFilter(queryMap)
+	if unknown := model.UnknownCurrencies(currency); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	var stored []*model.CurrentPrice
+	var next string
+	var err error
+	if path := strings.TrimPrefix(r.URL.Path, "/prices/"); path == "latest" {
+		stored, err = s.prices.Latest(r.Context(), assets)
+	} else {
+		timestamp, parseErr := strconv.ParseInt(path, 10, 64)
+		if parseErr != nil {
+			http.NotFound(w, r)
+			return
+		}
+		at := time.Unix(timestamp, 0)
+		stored, next, err = s.prices.GetRange(r.Context(), repository.PriceQuery{
+			From:       at,
+			To:         at,
+			Assets:     assets,
+			Currencies: s.hub.StoredCurrencies(currency),
+			Limit:      maxPageSize,
+			Cursor:     queryMap.Get("cursor"),
+		})
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == nil && len(stored) == 0 {
+			http.Error(w, "price not found", http.StatusNotFound)
+			return
+		}
+	}
+	if err != nil {
+		log.Err(err).Msg("error reading prices")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	s.writePrices(w, stored, currency, next)
+}
+
+// writePrices completes stored prices like replayed ones and writes those quoting one of currency, the store
+// only returns prices quoting one of its sources but a derivation can still fail.
+func (s *Server) writePrices(w http.ResponseWriter, stored []*model.CurrentPrice, currency []string, next string) {
+	filter := hub.Filter{Currencies: currency}
+	page := pricesPage{Prices: make([]PriceMsg, 0, len(stored)), NextCursor: next}
+	for _, price := range stored {
+		price = s.hub.Complete(price)
+		if filter.Matches(price) {
+			page.Prices = append(page.Prices, newPriceMsg(price, currency))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Err(err).Msg("error writing prices")
+	}
+}
+
+// queryTime reads an optional unix time parameter, the zero time when it is missing.
+func queryTime(queryMap url.Values, name string) (time.Time, error) {
+	if !queryMap.Has(name) {
+		return time.Time{}, nil
+	}
+	timestamp, err := strconv.ParseInt(queryMap.Get(name), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a unix timestamp", name)
+	}
+	return time.Unix(timestamp, 0), nil
+}
+
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
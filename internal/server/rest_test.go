@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, handler http.HandlerFunc, target string) (int, map[string]interface{}) {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestPricesAPI(t *testing.T) {
+	stored := func(asset string, at int64, usd, eur int64) *model.CurrentPrice {
+		p := &model.CurrentPrice{Asset: asset, Seq: uint64(at), Time: model.CurrentPriceTime{UpdatedISO: time.Unix(at, 0).UTC()},
+			Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(usd, 0)}}}
+		if eur != 0 {
+			p.SetRate("EUR", model.CurrentPriceRate{Value: model.NewDecimal(eur, 0)})
+		}
+		return p
+	}
+	repo := mockRepo.NewMockPrices(t)
+	priceHub := hub.New(nil, nil, repo)
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+	srv.WithPrices(repo)
+
+	repo.On("Latest", mock.Anything, []string{"BTC", "ETH"}).
+		Return([]*model.CurrentPrice{stored("BTC", 20, 40000, 0), stored("ETH", 10, 2000, 1800)}, nil).Once()
+	code, body := get(t, srv.priceHandler, "/prices/latest?asset=BTC&asset=ETH")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"timedate": "1970-01-01T00:00:20Z", "asset": "BTC", "seq": float64(20), "price": float64(40000)},
+		map[string]interface{}{"timedate": "1970-01-01T00:00:10Z", "asset": "ETH", "seq": float64(10), "price": float64(2000)},
+	}, body["prices"])
+	require.NotContains(t, body, "next_cursor")
+
+	// the store leaves out prices not quoting a requested currency before paging
+	query := repository.PriceQuery{From: time.Unix(10, 0), To: time.Unix(30, 0), Currencies: []string{"EUR"}, Limit: 2}
+	repo.On("GetRange", mock.Anything, query).
+		Return([]*model.CurrentPrice{stored("ETH", 10, 2000, 1800)}, "next", nil).Once()
+	code, body = get(t, srv.pricesHandler, "/prices?from=10&to=30&limit=2&currency=eur")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"timedate": "1970-01-01T00:00:10Z", "asset": "ETH", "seq": float64(10), "price": float64(2000), "price_eur": float64(1800)},
+	}, body["prices"])
+	require.Equal(t, "next", body["next_cursor"])
+
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{Limit: defaultPageSize, Cursor: "bad"}).
+		Return(nil, "", repository.ErrInvalidCursor).Once()
+	code, _ = get(t, srv.pricesHandler, "/prices?cursor=bad")
+	require.Equal(t, http.StatusBadRequest, code)
+	for _, target := range []string{"/prices?limit=0", "/prices?limit=5000", "/prices?from=yesterday", "/prices?currency=XYZ"} {
+		code, _ = get(t, srv.pricesHandler, target)
+		require.Equal(t, http.StatusBadRequest, code, target)
+	}
+
+	// prices stored at the same time are paged too
+	at := time.Unix(20, 0)
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: at, To: at, Limit: maxPageSize}).
+		Return([]*model.CurrentPrice{stored("BTC", 20, 40000, 0)}, "next", nil).Once()
+	code, body = get(t, srv.priceHandler, "/prices/20")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, body["prices"], 1)
+	require.Equal(t, "next", body["next_cursor"])
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: at, To: at, Limit: maxPageSize, Cursor: "next"}).
+		Return([]*model.CurrentPrice{stored("ETH", 20, 2000, 0)}, "", nil).Once()
+	code, body = get(t, srv.priceHandler, "/prices/20?cursor=next")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, body["prices"], 1)
+	require.NotContains(t, body, "next_cursor")
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: at, To: at, Limit: maxPageSize, Cursor: "bad"}).
+		Return(nil, "", repository.ErrInvalidCursor).Once()
+	code, _ = get(t, srv.priceHandler, "/prices/20?cursor=bad")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	missing := time.Unix(21, 0)
+	repo.On("GetRange", mock.Anything, repository.PriceQuery{From: missing, To: missing, Limit: maxPageSize}).
+		Return(nil, "", nil).Once()
+	code, _ = get(t, srv.priceHandler, "/prices/21")
+	require.Equal(t, http.StatusNotFound, code)
+	code, _ = get(t, srv.priceHandler, "/prices/oldest")
+	require.Equal(t, http.StatusNotFound, code)
+
+	rec := httptest.NewRecorder()
+	srv.priceHandler(rec, httptest.NewRequest(http.MethodPost, "/prices/latest", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -2,156 +2,217 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"code.injective.org/service/pricefetcher/internal/attest"
+	"code.injective.org/service/pricefetcher/internal/audit"
+	"code.injective.org/service/pricefetcher/internal/candle"
 	"code.injective.org/service/pricefetcher/internal/config"
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/leader"
 	"code.injective.org/service/pricefetcher/internal/model"
 	"code.injective.org/service/pricefetcher/internal/repository"
-	"github.com/google/uuid"
+	"code.injective.org/service/pricefetcher/internal/stats"
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 )
 
-const queueBufferSize = 100
-
 type Server struct {
-	receiverCh chan *model.CurrentPrice
-	errorCh    chan error
+	hub        *hub.Hub
 	cfg        *config.Config
 	wsUpgrader websocket.Upgrader
-	repo       repository.Prices
-	mutex      sync.Mutex
-	multi      map[string]chan *model.CurrentPrice
+	attester   *attest.Attester
+	prover     *audit.Prover
+	elector    *leader.Elector
+	prices     repository.Prices
+	candles    *candle.Aggregator
+	stats      *stats.Service
 }
 
-func NewServer(
-	receiverCh chan *model.CurrentPrice,
-	errorCh chan error,
-	cfg *config.Config,
-	repo repository.Prices) (Server, error) {
-
+func NewServer(h *hub.Hub, cfg *config.Config) (Server, error) {
 	wsUpgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
 
-	multi := make(map[string]chan *model.CurrentPrice)
-	return Server{receiverCh: receiverCh, errorCh: errorCh, cfg: cfg, wsUpgrader: wsUpgrader, repo: repo, multi: multi}, nil
+	return Server{hub: h, cfg: cfg, wsUpgrader: wsUpgrader}, nil
+}
+
+// WithAttester serves the public key prices are signed with on /pubkey.
+func (s *Server) WithAttester(attester *attest.Attester) {
+	s.attester = attester
+}
+
+// queryFilter reads the currency and asset parameters, their names are case insensitive.
+func queryFilter(queryMap url.Values) (currency, assets []string) {
+	for k, v := range queryMap {
+		if strings.EqualFold(k, "currency") {
+			currency = append(currency, v...)
+		}
+		if strings.EqualFold(k, "asset") {
+			assets = append(assets, v...)
+		}
+	}
+	return currency, assets
 }
 
 func (s *Server) wsHandler(w http.ResponseWriter, r *http.Request) {
+	queryMap := r.URL.Query()
+	currency, assets := queryFilter(queryMap)
+
+	if unknown := model.UnknownCurrencies(currency); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	// clients may pick what happens when they fall behind, the hub default applies otherwise
+	policy, err := hub.ParsePolicy(queryMap.Get("policy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fields, err := s.statsFields(queryMap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// resuming from a sequence number replays exactly what the client missed
+	var resumeFrom uint64
+	resume := queryMap.Has("resume_from_seq")
+	if resume {
+		if resumeFrom, err = strconv.ParseUint(queryMap.Get("resume_from_seq"), 10, 64); err != nil {
+			http.Error(w, "resume_from_seq must be a sequence number", http.StatusBadRequest)
+			return
+		}
+	}
+
 	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-
-	// reading income messages
-	go func(conn *websocket.Conn) {
-		for {
-			// Read a message from the client.
-			messageType, message, err := conn.ReadMessage()
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			// Print the message to the console.
-			fmt.Println("Received:", message)
-			fmt.Println("Received messageType:", messageType)
+	defer conn.Close()
+
+	// the subscription lives as long as the client keeps the connection open
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// subscribe before replaying, the hub buffers what is published meanwhile and hands off to live updates
+	filter := hub.Filter{Assets: assets, Currencies: currency}
+	sub := s.hub.Subscribe(ctx, filter, policy)
+	defer sub.Close()
+
+	// reading client commands, a read error means the client is gone
+	commands := make(chan wsCommand)
+	go func() {
+		defer cancel()
+		if err := readCommands(ctx, conn, commands); err != nil && ctx.Err() == nil {
+			log.Debug().Msgf("websocket client %s left: %v", sub.ID(), err)
 		}
-	}(conn)
-
-	// buffered channel to keep prices in queue
-	queueMsg := make(chan *model.CurrentPrice, queueBufferSize)
-	defer func() {
-		s.mutex.Lock()
-		delete(s.multi, uuid.NewString())
-		s.mutex.Unlock()
-		close(queueMsg)
 	}()
 
-	s.mutex.Lock()
-	s.multi[uuid.NewString()] = queueMsg
-	s.mutex.Unlock()
-
-	var currency []string
-	queryMap := r.URL.Query()
-	for k, v := range queryMap {
-		if strings.EqualFold(k, "currency") {
-			currency = append(currency, v...)
+	if resume {
+		missed, err := s.hub.Resume(ctx, sub, resumeFrom)
+		if err != nil {
+			log.Err(err).Msgf("something wrong with legacy data %v", err)
 		}
-	}
-
-	if r.URL.Query().Has("since_date") {
+		if err = s.sendPrices(conn, sub, missed, fields); err != nil {
+			log.Err(err).Msgf("something wrong with connections %v", err)
+			return
+		}
+	} else if r.URL.Query().Has("since_date") {
 		since := r.URL.Query().Get("since_date")
 		d, err := strconv.Atoi(since)
 		if err != nil {
 			log.Err(err).Msgf("wrong since date, ignoring")
-			conn.Close()
 			return
 		}
 
-		legacy, err := s.repo.GetSinceDate(context.Background(), time.Unix(int64(d), 10))
+		legacy, err := s.hub.Replay(ctx, sub, time.Unix(int64(d), 10))
 		if err != nil {
 			log.Err(err).Msgf("something wrong with legacy data %v", err)
 		}
-		for _, rate := range legacy {
-			err = s.sendReceivedPrice(conn, rate, currency)
-			if err != nil {
-				log.Err(err).Msgf("something wrong with connections %v", err)
-				conn.Close()
-				return
-			}
+		if err = s.sendPrices(conn, sub, legacy, fields); err != nil {
+			log.Err(err).Msgf("something wrong with connections %v", err)
+			return
 		}
 	}
 
-	for {
+	// prices and command replies are written from here only, websocket connections allow a single writer
+	for open := true; open; {
 		select {
-		case rate := <-queueMsg:
-			log.Info().Msgf("received rate %v", rate)
-			err = s.sendReceivedPrice(conn, rate, currency)
-		case errMsg := <-s.errorCh:
-			if errMsg != nil {
-				log.Err(errMsg).Msgf("something goes wrong with channel %s", errMsg)
+		case rate, ok := <-sub.C():
+			if !ok {
+				open = false
+				break
+			}
+			if err = s.sendPrices(conn, sub, []*model.CurrentPrice{rate}, fields); err != nil {
+				return
+			}
+		case command := <-commands:
+			if err = s.handleCommand(ctx, conn, sub, command, fields); err != nil {
+				log.Err(err).Msg("error writing message to client")
+				return
 			}
 		}
 	}
-}
 
-func (s *Server) sendReceivedPrice(conn *websocket.Conn, rate *model.CurrentPrice, currency []string) error {
-	type PriceMsg struct {
-		TimeDate time.Time `json:"timedate"`
-		Price    float64   `json:"price,omitempty"`
-		PriceUSD float64   `json:"price_usd,omitempty"`
-		PriceEUR float64   `json:"price_eur,omitempty"`
-		PriceGBP float64   `json:"price_gbp,omitempty"`
-	}
-	message := PriceMsg{
-		TimeDate: rate.Time.UpdatedISO,
-		Price:    rate.Bpi.Usd.RateFloat,
+	// tell the client why the hub dropped it
+	if err = sub.Err(); err != nil {
+		closing := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error())
+		if err = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second)); err != nil {
+			log.Err(err).Msg("error closing connection")
+		}
 	}
+}
 
-	for _, cur := range currency {
-		if strings.EqualFold(cur, "usd") {
-			message.PriceUSD = rate.Bpi.Usd.RateFloat
-		}
+// statsHandler reports the queue depth and drop count of every subscriber.
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscribers": s.hub.Stats(),
+	})
+	if err != nil {
+		log.Err(err).Msg("error writing stats")
+	}
+}
 
-		if strings.EqualFold(cur, "eur") {
-			message.PriceEUR = rate.Bpi.Eur.RateFloat
-		}
+// pubKeyHandler serves the key prices are signed with so clients can verify them offline.
+func (s *Server) pubKeyHandler(w http.ResponseWriter, r *http.Request) {
+	signer := s.attester.Signer()
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]string{
+		"algorithm":  "ed25519",
+		"key_id":     signer.KeyID(),
+		"public_key": base64.StdEncoding.EncodeToString(signer.PublicKey()),
+	})
+	if err != nil {
+		log.Err(err).Msg("error writing public key")
+	}
+}
 
-		if strings.EqualFold(cur, "gbp") {
-			message.PriceGBP = rate.Bpi.Gbp.RateFloat
+// sendPrices sends prices formatted for the currencies sub is currently subscribed to, with the statistics fields.
+func (s *Server) sendPrices(conn *websocket.Conn, sub *hub.Subscription, prices []*model.CurrentPrice, fields []stats.Field) error {
+	currency := sub.Filter().Currencies
+	for _, rate := range prices {
+		if err := s.sendReceivedPrice(conn, rate, currency, fields); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	marshalled, err := json.Marshal(message)
+func (s *Server) sendReceivedPrice(conn *websocket.Conn, rate *model.CurrentPrice, currency []string, fields []stats.Field) error {
+	marshalled, err := json.Marshal(s.priceMsg(rate, currency, fields))
 	if err != nil {
 		log.Err(err).Msg("error marshaling structure")
 	}
@@ -164,28 +225,29 @@ func (s *Server) sendReceivedPrice(conn *websocket.Conn, rate *model.CurrentPric
 	return nil
 }
 
-func (s *Server) runPipe() {
-	for {
-		select {
-		case rate := <-s.receiverCh:
-			log.Info().Msgf("received rate %v", rate)
-			for s2 := range s.multi {
-				s.multi[s2] <- rate
-			}
-		case errMsg := <-s.errorCh:
-			if errMsg != nil {
-				log.Err(errMsg).Msgf("something goes wrong with channel %s", errMsg)
-			}
-
-		}
-	}
-}
-
 func (s *Server) Run() error {
 	http.HandleFunc("/ws", s.wsHandler)
+	http.HandleFunc("/sse", s.sseHandler)
+	http.HandleFunc("/stats", s.statsHandler)
+	http.HandleFunc("/status", s.statusHandler)
+	if s.attester != nil {
+		http.HandleFunc("/pubkey", s.pubKeyHandler)
+	}
+	if s.prover != nil {
+		http.HandleFunc("/proof", s.proofHandler)
+	}
+	if s.prices != nil {
+		http.HandleFunc("/prices", s.pricesHandler)
+		http.HandleFunc("/prices/", s.priceHandler)
+	}
+	if s.stats != nil {
+		http.HandleFunc("/prices/stats", s.priceStatsHandler)
+	}
+	if s.candles != nil {
+		http.HandleFunc("/candles", s.candlesHandler)
+		http.HandleFunc("/ws/candles", s.candlesWsHandler)
+	}
 	log.Info().Msgf("server started on %s", s.cfg.Listen)
 
-	go s.runPipe()
-
 	return http.ListenAndServe(s.cfg.Listen, nil)
 }
@@ -5,9 +5,14 @@ import (
 	"context"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"code.injective.org/service/pricefetcher/internal/hub"
 	"code.injective.org/service/pricefetcher/internal/model"
 	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
 	"github.com/gorilla/websocket"
@@ -33,7 +38,12 @@ func TestSuccessReceived(t *testing.T) {
 		panic(err)
 	}
 
-	srv, err := NewServer(receiver, errors, cfg, mockPrices)
+	ctx, cancelHub := context.WithCancel(context.Background())
+	defer cancelHub()
+	priceHub := hub.New(receiver, errors, mockPrices)
+	go priceHub.Run(ctx)
+
+	srv, err := NewServer(priceHub, cfg)
 	if err != nil {
 		panic(err)
 	}
@@ -55,27 +65,27 @@ func TestSuccessReceived(t *testing.T) {
 		Time:       model.CurrentPriceTime{UpdatedISO: date},
 		Disclaimer: "disclamer",
 		ChartName:  "chart_name",
-		Bpi: model.CurrentPriceBpi{
-			Usd: model.CurrentPriceRate{
+		Bpi: map[string]model.CurrentPriceRate{
+			"USD": {
 				Code:        "USD",
 				Symbol:      "USD",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
-			Gbp: model.CurrentPriceRate{
+			"GBP": {
 				Code:        "GBP",
 				Symbol:      "GBP",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
-			Eur: model.CurrentPriceRate{
+			"EUR": {
 				Code:        "EUR",
 				Symbol:      "EUR",
 				Rate:        "1000",
 				Description: "desc",
-				RateFloat:   1.000,
+				Value:       model.MustParseDecimal("1.000"),
 			},
 		},
 	}
@@ -89,8 +99,10 @@ func TestSuccessReceived(t *testing.T) {
 	require.Equal(t, string(msg), `{"timedate":"1970-01-01T08:00:00.00000001+08:00","price":1}`)
 
 	sinceDate := time.Unix(1706015736, 10)
-	samplePrice.Bpi.Usd.RateFloat = 2
-	mockPrices.On("GetSinceDate", mock.Anything, sinceDate).Return([]*model.CurrentPrice{samplePrice}, nil).Once()
+	usd := samplePrice.Bpi["USD"]
+	usd.Value = model.NewDecimal(2, 0)
+	samplePrice.Bpi["USD"] = usd
+	mockPrices.On("GetSinceDate", mock.Anything, sinceDate, []string(nil)).Return([]*model.CurrentPrice{samplePrice}, nil).Once()
 
 	wsClient2, _, err := websocket.DefaultDialer.Dial("ws://0.0.0.0:8080/ws?since_date=1706015736", nil)
 	if err != nil {
@@ -139,4 +151,123 @@ func TestSuccessReceived(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, msgType, 1)
 	require.Equal(t, string(msg), `{"timedate":"1970-01-01T08:00:00.00000001+08:00","price":2,"price_usd":2,"price_eur":1}`)
+
+	// subscriber to several assets on one connection must not receive other assets
+	wsClient4, _, err := websocket.DefaultDialer.Dial("ws://0.0.0.0:8080/ws?asset=ETH&asset=INJ", nil)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer wsClient4.Close()
+
+	receiver <- samplePrice
+	ethPrice := *samplePrice
+	ethPrice.Asset = "ETH"
+	receiver <- &ethPrice
+
+	msgType, msg, err = wsClient4.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, msgType, 1)
+	require.Equal(t, string(msg), `{"timedate":"1970-01-01T08:00:00.00000001+08:00","asset":"ETH","price":2}`)
+
+	// currencies are not limited to USD/EUR/GBP
+	wsClient5, _, err := websocket.DefaultDialer.Dial("ws://0.0.0.0:8080/ws?currency=JPY&currency=chf", nil)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer wsClient5.Close()
+
+	samplePrice.Bpi["JPY"] = model.CurrentPriceRate{Code: "JPY", Value: model.MustParseDecimal("300")}
+	samplePrice.Bpi["CHF"] = model.CurrentPriceRate{Code: "CHF", Value: model.MustParseDecimal("1.5")}
+	receiver <- samplePrice
+
+	msgType, msg, err = wsClient5.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, msgType, 1)
+	require.Equal(t, string(msg), `{"timedate":"1970-01-01T08:00:00.00000001+08:00","price":2,"price_jpy":300,"price_chf":1.5}`)
+
+	// unknown currency must be rejected explicitly
+	_, resp, err := websocket.DefaultDialer.Dial("ws://0.0.0.0:8080/ws?currency=XYZ", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestConnectionChurn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receiver := make(chan *model.CurrentPrice)
+	priceHub := hub.New(receiver, make(chan error), nil)
+	go priceHub.Run(ctx)
+
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(srv.wsHandler))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "?currency=USD"
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 50)
+	for i := 0; i < 2000; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ws.Close()
+		}()
+	}
+	wg.Wait()
+
+	// every closed connection has to leave the fan-out
+	require.Eventually(t, func() bool { return priceHub.Count() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	// live clients keep receiving while others churn
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return priceHub.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	receiver <- &model.CurrentPrice{Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(3, 0)}}}
+	_, msg, err := ws.ReadMessage()
+	require.NoError(t, err)
+	require.Contains(t, string(msg), `"price_usd":3`)
+}
+
+func TestSlowConsumerDisconnected(t *testing.T) {
+	priceHub := hub.New(nil, nil, nil)
+	srv, err := NewServer(priceHub, nil)
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(srv.wsHandler))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?policy=block", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"?policy=disconnect", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.Eventually(t, func() bool { return priceHub.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	// the client doesn't read, so its queue eventually overflows
+	price := &model.CurrentPrice{Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(3, 0)}}}
+	require.Eventually(t, func() bool {
+		for i := 0; i < 1000; i++ {
+			priceHub.Publish(price)
+		}
+		return priceHub.Count() == 0
+	}, 10*time.Second, time.Millisecond)
+
+	for {
+		if _, _, err = ws.ReadMessage(); err != nil {
+			break
+		}
+	}
+	require.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err.Error())
+	require.Contains(t, err.Error(), hub.ErrSlowConsumer.Error())
 }
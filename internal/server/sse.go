@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultSSEHeartbeat applies when the server runs without configuration.
+const defaultSSEHeartbeat = 15 * time.Second
+
+// sseHandler streams the frames /ws sends as text/event-stream, for clients behind proxies breaking websockets.
+// It takes the same parameters as /ws. Stored prices carry their sequence number as event id, so a reconnecting
+// EventSource sends it back in Last-Event-ID and gets exactly what it missed. Ids only grow along a stream, a price
+// sent after a later one goes without.
+func (s *Server) sseHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	queryMap := r.URL.Query()
+	currency, assets := queryFilter(queryMap)
+	if unknown := model.UnknownCurrencies(currency); len(unknown) > 0 {
+		http.Error(w, fmt.Sprintf("unknown currency %s", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	policy, err := hub.ParsePolicy(queryMap.Get("policy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the header set by a reconnecting EventSource wins over the parameter of the first request
+	var resumeFrom uint64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = queryMap.Get("resume_from_seq")
+	}
+	resume := lastEventID != ""
+	if resume {
+		if resumeFrom, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			http.Error(w, "Last-Event-ID must be a sequence number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	fields, err := s.statsFields(queryMap)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var since int64
+	if queryMap.Has("since_date") {
+		if since, err = strconv.ParseInt(queryMap.Get("since_date"), 10, 64); err != nil {
+			http.Error(w, "since_date must be a unix timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// keeps nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// subscribe before replaying, the hub buffers what is published meanwhile and hands off to live updates
+	sub := s.hub.Subscribe(r.Context(), hub.Filter{Assets: assets, Currencies: currency}, policy)
+	defer sub.Close()
+
+	var replayed []*model.CurrentPrice
+	if resume {
+		replayed, err = s.hub.Resume(r.Context(), sub, resumeFrom)
+	} else if queryMap.Has("since_date") {
+		replayed, err = s.hub.Replay(r.Context(), sub, time.Unix(since, 10))
+	}
+	if err != nil {
+		log.Err(err).Msgf("something wrong with legacy data %v", err)
+	}
+	if resume {
+		// replays are ordered by time, a resumed stream goes by seq so no id is sent before a smaller one
+		sort.SliceStable(replayed, func(i, j int) bool { return replayed[i].Seq < replayed[j].Seq })
+	}
+	lastID := resumeFrom
+	for _, rate := range replayed {
+		if err = s.writeEvent(w, rate, &lastID, currency, fields); err != nil {
+			log.Err(err).Msgf("something wrong with connections %v", err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := defaultSSEHeartbeat
+	if s.cfg != nil && s.cfg.SSEHeartbeat > 0 {
+		heartbeat = s.cfg.SSEHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	// the subscription is closed when the client goes away
+	for open := true; open; {
+		select {
+		case rate, ok := <-sub.C():
+			if !ok {
+				open = false
+				break
+			}
+			err = s.writeEvent(w, rate, &lastID, currency, fields)
+		case <-ticker.C:
+			// a comment line, ignored by EventSource
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err != nil {
+			log.Err(err).Msg("error writing message to client")
+			return
+		}
+		flusher.Flush()
+	}
+
+	// tell the client why the hub dropped it
+	if err = sub.Err(); err != nil {
+		if _, err = fmt.Fprintf(w, "event: close\ndata: %s\n\n", err); err == nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes rate as a message event holding the /ws frame. Its sequence number is the id when it comes after
+// lastID, the last one sent, as resuming from an id skips every smaller one.
+func (s *Server) writeEvent(w io.Writer, rate *model.CurrentPrice, lastID *uint64, currency []string, fields []stats.Field) error {
+	marshalled, err := json.Marshal(s.priceMsg(rate, currency, fields))
+	if err != nil {
+		return err
+	}
+	if rate.Seq > *lastID {
+		if _, err = fmt.Fprintf(w, "id: %d\n", rate.Seq); err != nil {
+			return err
+		}
+		*lastID = rate.Seq
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", marshalled)
+	return err
+}
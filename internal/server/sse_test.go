@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/config"
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/model"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent returns the lines of the next event or comment.
+func readEvent(t *testing.T, lines *bufio.Scanner) []string {
+	var event []string
+	for lines.Scan() {
+		if lines.Text() == "" {
+			return event
+		}
+		event = append(event, lines.Text())
+	}
+	t.Fatalf("stream ended: %v", lines.Err())
+	return nil
+}
+
+func TestSSE(t *testing.T) {
+	usd := func(asset string, seq uint64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: asset, Seq: seq, Bpi: map[string]model.CurrentPriceRate{
+			"USD": {Value: model.NewDecimal(int64(seq), 0)},
+		}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	repo.On("GetSinceSeq", mock.Anything, uint64(7), []string{"BTC"}).
+		Return([]*model.CurrentPrice{usd("BTC", 8), usd("BTC", 9)}, nil).Once()
+	priceHub := hub.New(nil, nil, repo)
+	srv, err := NewServer(priceHub, &config.Config{SSEHeartbeat: 50 * time.Millisecond})
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(srv.sseHandler))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "?currency=XYZ")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?asset=BTC&currency=USD", nil)
+	require.NoError(t, err)
+	// sent by a reconnecting EventSource
+	req.Header.Set("Last-Event-ID", "7")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	require.Equal(t, []string{"id: 8", `data: {"timedate":"0001-01-01T00:00:00Z","asset":"BTC","seq":8,"price":8,"price_usd":8}`},
+		readEvent(t, lines))
+	require.Equal(t, "id: 9", readEvent(t, lines)[0])
+
+	// the replayed update published live again is not sent twice, other assets are filtered out
+	priceHub.Publish(usd("BTC", 9))
+	priceHub.Publish(usd("ETH", 10))
+	priceHub.Publish(usd("BTC", 11))
+	event := readEvent(t, lines)
+	for event[0] == ": heartbeat" {
+		event = readEvent(t, lines)
+	}
+	require.Equal(t, "id: 11", event[0])
+
+	// idle streams are kept alive
+	require.Equal(t, []string{": heartbeat"}, readEvent(t, lines))
+
+	cancel()
+	require.Eventually(t, func() bool { return priceHub.Count() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSSEEventIDsGrow(t *testing.T) {
+	at := func(seq uint64, second int64) *model.CurrentPrice {
+		return &model.CurrentPrice{Asset: "BTC", Seq: seq, Time: model.CurrentPriceTime{UpdatedISO: time.Unix(second, 0)},
+			Bpi: map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(int64(seq), 0)}}}
+	}
+	repo := mockRepo.NewMockPrices(t)
+	// seq 6 was stored after seq 5 but carries an earlier price time
+	repo.On("GetSinceSeq", mock.Anything, uint64(4), []string(nil)).
+		Return([]*model.CurrentPrice{at(5, 200), at(6, 100)}, nil).Once()
+	repo.On("GetSinceDate", mock.Anything, time.Unix(50, 10), []string(nil)).
+		Return([]*model.CurrentPrice{at(5, 200), at(6, 100)}, nil).Once()
+	priceHub := hub.New(nil, nil, repo)
+	srv, err := NewServer(priceHub, &config.Config{SSEHeartbeat: time.Minute})
+	require.NoError(t, err)
+	// closed after the streams, it waits for their handlers
+	ts := httptest.NewServer(http.HandlerFunc(srv.sseHandler))
+	t.Cleanup(ts.Close)
+
+	stream := func(query string) *bufio.Scanner {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+query, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return bufio.NewScanner(resp.Body)
+	}
+
+	// resumed streams are replayed by seq
+	lines := stream("?resume_from_seq=4")
+	require.Equal(t, "id: 5", readEvent(t, lines)[0])
+	require.Equal(t, "id: 6", readEvent(t, lines)[0])
+
+	// replays by time send the price coming after a later one without id
+	lines = stream("?since_date=50")
+	require.Equal(t, "id: 6", readEvent(t, lines)[0])
+	event := readEvent(t, lines)
+	require.Len(t, event, 1)
+	require.Contains(t, event[0], `"seq":5`)
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.injective.org/service/pricefetcher/internal/leader"
+	"github.com/rs/zerolog/log"
+)
+
+// WithElector reports the fetcher leadership on /status.
+func (s *Server) WithElector(elector *leader.Elector) {
+	s.elector = elector
+}
+
+// statusHandler reports the role of the instance and, when leader election runs, who leads.
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{
+		"subscribers": s.hub.Count(),
+	}
+	if s.cfg != nil {
+		status["role"] = s.cfg.Role
+	}
+	if s.elector != nil {
+		status["leadership"] = s.elector.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Err(err).Msg("error writing status")
+	}
+}
@@ -0,0 +1,245 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// pageSize is how many stored prices are read at once for windows older than the ones kept in memory.
+const pageSize = 1000
+
+// ErrNoPrices is returned for a window without any tick of the asset in the currency.
+var ErrNoPrices = errors.New("no prices within the window")
+
+// Value is a streamed statistic, see Service.Values.
+type Value struct {
+	Name  string
+	Value model.Decimal
+}
+
+type seriesKey struct {
+	asset    string
+	currency string
+}
+
+// series holds the ticks of an asset in one currency within MaxWindow of the newest one, ordered by time.
+type series struct {
+	ticks []tick
+	// version changes with every tick, it invalidates cached windows
+	version uint64
+	cache   map[time.Duration]cached
+}
+
+type cached struct {
+	at      time.Time
+	version uint64
+	stats   Stats
+}
+
+// Service computes statistics of the prices it is given and of the stored ones. The ticks of the last MaxWindow
+// are kept in memory, they are rebuilt from storage on start so statistics survive restarts.
+type Service struct {
+	repo     repository.Prices
+	complete func(*model.CurrentPrice) *model.CurrentPrice
+	now      func() time.Time
+
+	mutex   sync.RWMutex
+	series  map[seriesKey]*series
+	rebuilt bool
+}
+
+// NewService reads stored prices from repo, complete adds the rates derived from them as the live ones have them.
+func NewService(repo repository.Prices, complete func(*model.CurrentPrice) *model.CurrentPrice) *Service {
+	if complete == nil {
+		complete = func(price *model.CurrentPrice) *model.CurrentPrice { return price }
+	}
+	return &Service{
+		repo:     repo,
+		complete: complete,
+		now:      time.Now,
+		series:   make(map[seriesKey]*series),
+	}
+}
+
+// Rebuild loads the prices stored within MaxWindow. Queries are answered from storage until it is done.
+func (s *Service) Rebuild(ctx context.Context) error {
+	stored, err := s.repo.GetSinceDate(ctx, s.now().Add(-MaxWindow), nil)
+	if err != nil {
+		return err
+	}
+	for _, price := range stored {
+		s.Add(s.complete(price))
+	}
+	s.mutex.Lock()
+	s.rebuilt = true
+	s.mutex.Unlock()
+	log.Info().Msgf("rebuilt price statistics from %d stored prices", len(stored))
+	return nil
+}
+
+// Run adds the prices read from in to the statistics and forwards them to out, until ctx is done.
+func (s *Service) Run(ctx context.Context, in <-chan *model.CurrentPrice, out chan<- *model.CurrentPrice) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("exiting")
+			return
+		case price := <-in:
+			s.Add(price)
+			select {
+			case out <- price:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Add records the rates of price. Ticks are kept by the second as stored prices are, the latest of the same
+// second wins.
+func (s *Service) Add(price *model.CurrentPrice) {
+	at := price.Time.UpdatedISO.UTC().Truncate(time.Second)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for code, rate := range price.Bpi {
+		if rate.Value.IsZero() {
+			continue
+		}
+		k := seriesKey{asset: price.AssetSymbol(), currency: strings.ToUpper(code)}
+		ser, ok := s.series[k]
+		if !ok {
+			ser = &series{cache: make(map[time.Duration]cached)}
+			s.series[k] = ser
+		}
+		ser.add(tick{at: at, value: rate.Value})
+	}
+}
+
+func (ser *series) add(t tick) {
+	i := sort.Search(len(ser.ticks), func(i int) bool { return !ser.ticks[i].at.Before(t.at) })
+	switch {
+	case i < len(ser.ticks) && ser.ticks[i].at.Equal(t.at):
+		ser.ticks[i] = t
+	case i == len(ser.ticks):
+		ser.ticks = append(ser.ticks, t)
+	default:
+		ser.ticks = append(ser.ticks, tick{})
+		copy(ser.ticks[i+1:], ser.ticks[i:])
+		ser.ticks[i] = t
+	}
+	ser.version++
+
+	oldest := ser.ticks[len(ser.ticks)-1].at.Add(-MaxWindow)
+	if pruned := sort.Search(len(ser.ticks), func(i int) bool { return !ser.ticks[i].at.Before(oldest) }); pruned > 0 {
+		ser.ticks = append(ser.ticks[:0], ser.ticks[pruned:]...)
+	}
+}
+
+// within returns the ticks between from and to, both inclusive.
+func (ser *series) within(from, to time.Time) []tick {
+	start := sort.Search(len(ser.ticks), func(i int) bool { return !ser.ticks[i].at.Before(from) })
+	end := sort.Search(len(ser.ticks), func(i int) bool { return ser.ticks[i].at.After(to) })
+	if start >= end {
+		return nil
+	}
+	return ser.ticks[start:end]
+}
+
+// Query returns the statistics of asset quoted in currency between from and to, up to now when to is zero.
+func (s *Service) Query(ctx context.Context, asset, currency string, from, to time.Time) (Stats, error) {
+	asset, currency = strings.ToUpper(asset), strings.ToUpper(currency)
+	now := s.now()
+	if to.IsZero() {
+		to = now
+	}
+	from, to = from.UTC(), to.UTC()
+
+	var ticks []tick
+	s.mutex.RLock()
+	inMemory := s.rebuilt && !from.Before(now.Add(-MaxWindow))
+	if ser, ok := s.series[seriesKey{asset: asset, currency: currency}]; inMemory && ok {
+		ticks = append(ticks, ser.within(from, to)...)
+	}
+	s.mutex.RUnlock()
+
+	if !inMemory {
+		var err error
+		if ticks, err = s.stored(ctx, asset, currency, from, to); err != nil {
+			return Stats{}, err
+		}
+	}
+	res, ok := compute(ticks, currency, from, to)
+	if !ok {
+		return Stats{}, ErrNoPrices
+	}
+	res.Asset = asset
+	return res, nil
+}
+
+// stored reads the ticks between from and to back from storage.
+func (s *Service) stored(ctx context.Context, asset, currency string, from, to time.Time) ([]tick, error) {
+	ser := &series{}
+	query := repository.PriceQuery{From: from, To: to, Assets: []string{asset}, Limit: pageSize}
+	for {
+		page, next, err := s.repo.GetRange(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, price := range page {
+			if rate, ok := s.complete(price).Rate(currency); ok && !rate.Value.IsZero() {
+				// pages are ordered by time, the latest price of a second wins as in Add
+				at := price.Time.UpdatedISO.UTC().Truncate(time.Second)
+				if n := len(ser.ticks); n > 0 && ser.ticks[n-1].at.Equal(at) {
+					ser.ticks[n-1].value = rate.Value
+					continue
+				}
+				ser.ticks = append(ser.ticks, tick{at: at, value: rate.Value})
+			}
+		}
+		if next == "" {
+			return ser.within(from, to), nil
+		}
+		query.Cursor = next
+	}
+}
+
+// Values returns fields of the asset of price quoted in currency, each over its window up to the price. Fields
+// without ticks in their window are left out.
+func (s *Service) Values(price *model.CurrentPrice, currency string, fields []Field) []Value {
+	if len(fields) == 0 {
+		return nil
+	}
+	to := price.Time.UpdatedISO.UTC()
+	k := seriesKey{asset: price.AssetSymbol(), currency: strings.ToUpper(currency)}
+
+	// every subscriber asks for the same price, the first one computes the windows
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ser, ok := s.series[k]
+	if !ok {
+		return nil
+	}
+	values := make([]Value, 0, len(fields))
+	for _, field := range fields {
+		window, ok := ser.cache[field.Window]
+		if !ok || !window.at.Equal(to) || window.version != ser.version {
+			from := to.Add(-field.Window)
+			res, ok := compute(ser.within(from, to), k.currency, from, to)
+			if !ok {
+				continue
+			}
+			window = cached{at: to, version: ser.version, stats: res}
+			ser.cache[field.Window] = window
+		}
+		values = append(values, Value{Name: field.Name, Value: window.stats.Value(field.Stat)})
+	}
+	return values
+}
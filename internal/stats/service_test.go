@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/repository"
+	mockRepo "code.injective.org/service/pricefetcher/internal/repository/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+
+func price(second int, usd int64) *model.CurrentPrice {
+	return &model.CurrentPrice{
+		Asset: "BTC",
+		Time:  model.CurrentPriceTime{UpdatedISO: base.Add(time.Duration(second) * time.Second)},
+		Bpi:   map[string]model.CurrentPriceRate{"USD": {Value: model.NewDecimal(usd, 0)}},
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"twap_1h, change_24h", "TWAP_1h", "min_1d"})
+	require.NoError(t, err)
+	require.Equal(t, []Field{
+		{Name: "twap_1h", Stat: TWAP, Window: time.Hour},
+		{Name: "change_24h", Stat: Change, Window: 24 * time.Hour},
+		{Name: "min_1d", Stat: Min, Window: 24 * time.Hour},
+	}, fields)
+	for _, wrong := range []string{"twap", "avg_1h", "twap_0h", "twap_2d", "max_1x"} {
+		_, err = ParseFields([]string{wrong})
+		require.Error(t, err, wrong)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	s := NewService(mockRepo.NewMockPrices(t), nil)
+	s.now = func() time.Time { return base.Add(time.Minute) }
+	s.rebuilt = true
+	// 100 for 10s, 200 for 30s, 50 until the end of the minute
+	for _, p := range []*model.CurrentPrice{price(0, 100), price(40, 50), price(10, 200)} {
+		s.Add(p)
+	}
+
+	res, err := s.Query(context.Background(), "btc", "usd", base, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 3, res.Ticks)
+	require.Equal(t, "BTC", res.Asset)
+	require.Equal(t, "50", res.Last.String())
+	require.Equal(t, "133.33333333", res.TWAP.String())
+	require.Equal(t, "50", res.Min.String())
+	require.Equal(t, "200", res.Max.String())
+	require.Equal(t, "62.36095645", res.StdDev.String())
+	require.Equal(t, "-50", res.Change.String())
+
+	_, err = s.Query(context.Background(), "ETH", "USD", base, time.Time{})
+	require.ErrorIs(t, err, ErrNoPrices)
+}
+
+func TestQueryStdDevOfLargePrices(t *testing.T) {
+	s := NewService(mockRepo.NewMockPrices(t), nil)
+	s.now = func() time.Time { return base.Add(time.Minute) }
+	s.rebuilt = true
+	for i, value := range []string{"1000000000.01", "1000000000.02", "1000000000.03"} {
+		p := price(i, 0)
+		p.SetRate("USD", model.CurrentPriceRate{Value: model.MustParseDecimal(value)})
+		s.Add(p)
+	}
+
+	// the squares of the prices are too large for float64 to keep their spread
+	res, err := s.Query(context.Background(), "BTC", "USD", base, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, "0.00816497", res.StdDev.String())
+}
+
+func TestQueryReadsStorage(t *testing.T) {
+	repo := mockRepo.NewMockPrices(t)
+	s := NewService(repo, nil)
+	from := base.Add(-48 * time.Hour)
+	s.now = func() time.Time { return base }
+
+	// older windows are read back page by page
+	query := repository.PriceQuery{From: from, To: base, Assets: []string{"BTC"}, Limit: pageSize}
+	repo.On("GetRange", mock.Anything, query).Return([]*model.CurrentPrice{price(-120, 100)}, "next", nil).Once()
+	query.Cursor = "next"
+	repo.On("GetRange", mock.Anything, query).Return([]*model.CurrentPrice{price(-60, 110)}, "", nil).Once()
+
+	res, err := s.Query(context.Background(), "BTC", "USD", from, base)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Ticks)
+	require.Equal(t, "10", res.Change.String())
+	require.Equal(t, "105", res.TWAP.String())
+}
+
+func TestRebuildAndValues(t *testing.T) {
+	repo := mockRepo.NewMockPrices(t)
+	s := NewService(repo, nil)
+	s.now = func() time.Time { return base.Add(time.Hour) }
+	repo.On("GetSinceDate", mock.Anything, base.Add(-23*time.Hour), []string(nil)).
+		Return([]*model.CurrentPrice{price(-2*3600, 80), price(0, 100)}, nil).Once()
+	require.NoError(t, s.Rebuild(context.Background()))
+
+	fields, err := ParseFields([]string{"change_24h,change_1h,max_1h"})
+	require.NoError(t, err)
+	// a restarted instance streams the same statistics as the one that was running
+	s.Add(price(1800, 120))
+	values := s.Values(price(1800, 120), "USD", fields)
+	require.Len(t, values, 3)
+	for i, expected := range []Value{
+		{Name: "change_24h", Value: model.NewDecimal(50, 0)},
+		{Name: "change_1h", Value: model.NewDecimal(20, 0)},
+		{Name: "max_1h", Value: model.NewDecimal(120, 0)},
+	} {
+		require.Equal(t, expected.Name, values[i].Name)
+		require.True(t, expected.Value.Equal(values[i].Value), values[i].Value.String())
+	}
+	// a late tick invalidates the cached windows
+	s.Add(price(900, 150))
+	values = s.Values(price(1800, 120), "USD", fields)
+	require.Equal(t, "150", values[2].Value.String())
+}
@@ -0,0 +1,180 @@
+// Package stats computes rolling statistics over the price history.
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.injective.org/service/pricefetcher/internal/model"
+)
+
+// MaxWindow is the longest window statistics are streamed over, the prices within it are kept in memory.
+const MaxWindow = 24 * time.Hour
+
+// changeScale is the number of digits kept after the decimal point of percent changes.
+const changeScale = 4
+
+// varianceScale is the number of digits kept after the decimal point of the mean and variance of prices.
+const varianceScale = 18
+
+// Statistics a Field may stream.
+const (
+	TWAP   = "twap"
+	Min    = "min"
+	Max    = "max"
+	StdDev = "stddev"
+	Change = "change"
+)
+
+// Stats summarizes the ticks of an asset quoted in one currency between From and To, both inclusive.
+type Stats struct {
+	Asset    string        `json:"asset"`
+	Currency string        `json:"currency"`
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Ticks    int           `json:"ticks"`
+	Last     model.Decimal `json:"last"`
+	// TWAP weighs every tick by the time until the next one, the last one until To.
+	TWAP   model.Decimal `json:"twap"`
+	Min    model.Decimal `json:"min"`
+	Max    model.Decimal `json:"max"`
+	StdDev model.Decimal `json:"stddev"`
+	// Change is the percent change from the first tick to the last one.
+	Change model.Decimal `json:"change"`
+}
+
+// Value returns the statistic named stat.
+func (s Stats) Value(stat string) model.Decimal {
+	switch stat {
+	case TWAP:
+		return s.TWAP
+	case Min:
+		return s.Min
+	case Max:
+		return s.Max
+	case StdDev:
+		return s.StdDev
+	default:
+		return s.Change
+	}
+}
+
+// Field is a statistic subscribers may have streamed with every price, over the window up to the price.
+type Field struct {
+	// Name is the statistic and the window, e.g. "twap_1h" or "change_24h".
+	Name   string
+	Stat   string
+	Window time.Duration
+}
+
+// ParseFields parses field names, values may hold several separated by commas.
+func ParseFields(values []string) ([]Field, error) {
+	var fields []Field
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			field, err := parseField(name)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+func parseField(name string) (Field, error) {
+	stat, window, ok := strings.Cut(name, "_")
+	switch {
+	case !ok:
+		return Field{}, fmt.Errorf("unknown statistic %q, expected e.g. twap_1h", name)
+	case stat != TWAP && stat != Min && stat != Max && stat != StdDev && stat != Change:
+		return Field{}, fmt.Errorf("unknown statistic %q, expected twap, min, max, stddev or change", stat)
+	}
+	duration, err := ParseWindow(window)
+	if err != nil {
+		return Field{}, err
+	}
+	if duration > MaxWindow {
+		return Field{}, fmt.Errorf("window of %s is longer than %s", name, MaxWindow)
+	}
+	return Field{Name: name, Stat: stat, Window: duration}, nil
+}
+
+// ParseWindow parses durations like "15m" or "24h", and days like "1d".
+func ParseWindow(value string) (time.Duration, error) {
+	var window time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		window, err = time.ParseDuration(value)
+	}
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("wrong window %q, expected e.g. 1h or 1d", value)
+	}
+	return window, nil
+}
+
+type tick struct {
+	at    time.Time
+	value model.Decimal
+}
+
+// compute summarizes ticks, ordered by time and all within from and to.
+func compute(ticks []tick, currency string, from, to time.Time) (Stats, bool) {
+	if len(ticks) == 0 {
+		return Stats{}, false
+	}
+	first, last := ticks[0], ticks[len(ticks)-1]
+	res := Stats{Currency: currency, From: from, To: to, Ticks: len(ticks), Last: last.value, Min: first.value, Max: first.value}
+
+	var weighted, sum model.Decimal
+	for i, t := range ticks {
+		if t.value.Cmp(res.Min) < 0 {
+			res.Min = t.value
+		}
+		if t.value.Cmp(res.Max) > 0 {
+			res.Max = t.value
+		}
+		until := to
+		if i+1 < len(ticks) {
+			until = ticks[i+1].at
+		}
+		weighted = weighted.Add(t.value.Mul(model.NewDecimal(until.Sub(t.at).Milliseconds(), 0)))
+		sum = sum.Add(t.value)
+	}
+
+	scale := model.DecimalSpecFor(currency).Scale
+	if total := to.Sub(first.at).Milliseconds(); total > 0 {
+		res.TWAP = weighted.Quo(model.NewDecimal(total, 0), scale)
+	} else {
+		res.TWAP = last.value
+	}
+	// the variance is summed exactly, float64 doesn't keep the spread of large prices
+	count := model.NewDecimal(int64(len(ticks)), 0)
+	mean := sum.Quo(count, varianceScale)
+	var deviations model.Decimal
+	for _, t := range ticks {
+		deviation := t.value.Sub(mean)
+		deviations = deviations.Add(deviation.Mul(deviation))
+	}
+	variance := deviations.Quo(count, varianceScale)
+	if stdDev, err := model.DecimalFromFloat(math.Sqrt(variance.Float64())); err == nil {
+		res.StdDev = model.RoundRate(currency, stdDev)
+	}
+	if !first.value.IsZero() {
+		res.Change = last.value.Sub(first.value).Mul(model.NewDecimal(100, 0)).Quo(first.value, changeScale)
+	}
+	return res, true
+}
@@ -9,10 +9,21 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"os"
+	"sync"
 
+	"code.injective.org/service/pricefetcher/attestation"
+	"code.injective.org/service/pricefetcher/internal/attest"
+	"code.injective.org/service/pricefetcher/internal/audit"
+	"code.injective.org/service/pricefetcher/internal/broker"
+	"code.injective.org/service/pricefetcher/internal/candle"
 	"code.injective.org/service/pricefetcher/internal/client/provider"
 	"code.injective.org/service/pricefetcher/internal/config"
+	"code.injective.org/service/pricefetcher/internal/fx"
+	"code.injective.org/service/pricefetcher/internal/hub"
+	"code.injective.org/service/pricefetcher/internal/leader"
 	"code.injective.org/service/pricefetcher/internal/model"
+	"code.injective.org/service/pricefetcher/internal/stats"
 )
 
 func main() {
@@ -24,12 +35,56 @@ func main() {
 		panic(err)
 	}
 
-	coin := provider.NewCoinDeskProvider("BTC")
+	if err = applyDecimalSpecs(cfg); err != nil {
+		panic(err)
+	}
 
-	receiver := make(chan *model.CurrentPrice)
+	defs := []provider.Definition{{Type: provider.TypeCoinDesk, Name: "coindesk"}}
+	if cfg.ProvidersFile != "" {
+		defs, err = provider.LoadDefinitions(cfg.ProvidersFile)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// fetchers or the change stream write into fetched, optional stages in between forward to receiver which is fanned out
+	fetched := make(chan *model.CurrentPrice)
+	receiver := fetched
 	errors := make(chan error)
-	defer close(receiver)
+	defer close(fetched)
 	defer close(errors)
+	// stored prices are completed by the same stages when replayed
+	var replayStages []func(*model.CurrentPrice) *model.CurrentPrice
+	// stored prices are looked up by the currencies derived ones are derived from
+	var currencySources func(string) []string
+
+	if cfg.FXRatesFile != "" {
+		table, err := fx.LoadTable(cfg.FXRatesFile)
+		if err != nil {
+			panic(err)
+		}
+		crossRates := fx.NewCrossRates(table)
+		replayStages = append(replayStages, crossRates.Apply)
+		currencySources = crossRates.Sources
+		next := make(chan *model.CurrentPrice)
+		go crossRates.Run(ctx, receiver, next)
+		receiver = next
+	}
+
+	// signing goes last so derived rates are attested too
+	var attester *attest.Attester
+	if cfg.SigningKeyFile != "" {
+		key, err := attestation.LoadPrivateKey(cfg.SigningKeyFile)
+		if err != nil {
+			panic(err)
+		}
+		attester = attest.NewAttester(attestation.NewSigner(key))
+		log.Info().Msgf("signing prices with key %s", attester.Signer().KeyID())
+		replayStages = append(replayStages, attester.Apply)
+		next := make(chan *model.CurrentPrice)
+		go attester.Run(ctx, receiver, next)
+		receiver = next
+	}
 
 	// setup DB connection
 	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoDBURL))
@@ -50,8 +105,166 @@ func main() {
 
 	// run fetcher to receive prices
 	pricesRepo := repository.NewPrices(db)
-	fetcher := client.NewPriceFetcher(pricesRepo, coin, cfg.FetchInterval, true)
-	go fetcher.RunPriceFetcher(ctx, receiver, errors)
+	quarantineRepo := repository.NewQuarantine(db)
+	bounds, err := client.ParseBounds(cfg.ValidationBounds)
+	if err != nil {
+		panic(err)
+	}
+	// every fetcher gets its own validator, sources of an asset would otherwise share medians and timestamps
+	rules := client.ValidationRules{
+		MaxDeviation: cfg.ValidationMaxDeviation,
+		Window:       cfg.ValidationWindow,
+		Bounds:       bounds,
+		Monotonic:    cfg.ValidationMonotonic,
+		MaxAge:       cfg.ValidationMaxAge,
+	}
+
+	// seal stored prices into Merkle batches for auditors, whoever fetches seals
+	var prover *audit.Prover
+	var batchesRepo repository.Batches
+	if cfg.BatchInterval > 0 {
+		batchesRepo = repository.NewBatches(db)
+		prover = audit.NewProver(batchesRepo)
+	}
+
+	// behind a load balancer every instance broadcasts what the writer published, through
+	// the message bus when there is one or else the change stream
+	fetcherOut := fetched
+	switch bus := newBroker(cfg); {
+	case bus != nil:
+		defer bus.Close()
+		fetcherOut = make(chan *model.CurrentPrice)
+		go broker.PublishPrices(ctx, bus, fetcherOut, errors)
+		messages, err := bus.Subscribe(ctx, broker.AllPrices)
+		if err != nil {
+			panic(err)
+		}
+		go broker.ReceivePrices(ctx, messages, fetched)
+	case cfg.Role != config.RoleStandalone:
+		// fetched prices reach local subscribers through the change stream like everybody else's
+		fetcherOut = make(chan *model.CurrentPrice)
+		go func() {
+			for range fetcherOut {
+			}
+		}()
+		go repository.NewChangeStream(db).Watch(ctx, fetched, errors)
+	}
+
+	// fetchers run on a standalone instance, or on the writer holding the lease
+	// fetch returns once ctx is done and everything it started stopped writing
+	fetch := func(ctx context.Context) {
+		var running sync.WaitGroup
+		defer running.Wait()
+		start := func(run func()) {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				run()
+			}()
+		}
+
+		if batchesRepo != nil {
+			sealer := audit.NewSealer(batchesRepo, cfg.BatchSize)
+			start(func() { sealer.Run(ctx, cfg.BatchInterval) })
+		}
+
+		// a new leader reads the last rounds back from the store
+		var publisher *client.Publisher
+		if cfg.PublishMode == "deviation" {
+			publisher = client.NewPublisher(client.PublishPolicy{
+				DeviationBps: cfg.PublishDeviationBps,
+				Heartbeat:    cfg.PublishHeartbeat,
+			}, pricesRepo.LastRound)
+		}
+
+		// fetchers of every asset share it, so stored prices are broadcast in sequence order
+		sequencer := client.NewSequencer()
+		assets, groups := provider.GroupByAsset(defs)
+		for _, asset := range assets {
+			sources, err := provider.NewSources(groups[asset])
+			if err != nil {
+				panic(err)
+			}
+			streams, err := provider.NewStreams(groups[asset])
+			if err != nil {
+				panic(err)
+			}
+
+			if len(sources) > 0 {
+				log.Info().Msgf("starting price fetcher for %s", asset)
+				fetcher := client.NewPriceFetcher(pricesRepo, newPriceProvider(cfg, sources), cfg.FetchInterval, true).
+					WithValidator(client.NewValidator(rules), quarantineRepo).
+					WithPublisher(publisher).
+					WithSequencer(sequencer)
+				start(func() { fetcher.RunPriceFetcher(ctx, fetcherOut, errors) })
+			}
+			for _, stream := range streams {
+				log.Info().Msgf("starting stream provider %s for %s", stream.Name, asset)
+				streamFetcher := client.NewStreamFetcher(pricesRepo, stream.Provider, true).
+					WithValidator(client.NewValidator(rules), quarantineRepo).
+					WithPublisher(publisher).
+					WithSequencer(sequencer)
+				start(func() { streamFetcher.RunStreamFetcher(ctx, fetcherOut, errors) })
+			}
+		}
+	}
+
+	var elector *leader.Elector
+	switch {
+	case cfg.Role == config.RoleEdge:
+	case cfg.LeaderElection:
+		elector = leader.NewElector(repository.NewLeases(db), "fetcher", instanceID(cfg), cfg.LeaderLeaseTTL)
+		// a demoted leader stops writing before a follower may take over
+		go elector.Run(ctx, fetch)
+	default:
+		go fetch(ctx)
+	}
+
+	complete := func(price *model.CurrentPrice) *model.CurrentPrice {
+		for _, stage := range replayStages {
+			price = stage(price)
+		}
+		return price
+	}
+
+	// statistics see every broadcast price with its derived rates, and what was stored before the start
+	var statsService *stats.Service
+	if cfg.PriceStats {
+		statsService = stats.NewService(pricesRepo, complete)
+		go func() {
+			if err := statsService.Rebuild(ctx); err != nil {
+				log.Err(err).Msg("failed to rebuild price statistics")
+			}
+		}()
+		next := make(chan *model.CurrentPrice)
+		go statsService.Run(ctx, receiver, next)
+		receiver = next
+	}
+
+	// both transports subscribe to the hub
+	policy, err := hub.ParsePolicy(cfg.SlowConsumerPolicy)
+	if err != nil {
+		panic(err)
+	}
+	priceHub := hub.New(receiver, errors, pricesRepo).WithPolicy(policy)
+	if len(replayStages) > 0 {
+		priceHub.WithReplayTransform(complete)
+	}
+	if currencySources != nil {
+		priceHub.WithCurrencySources(currencySources)
+	}
+	go priceHub.Run(ctx)
+
+	// every instance builds the candles of the prices it broadcasts, merging them in the store is idempotent
+	intervals, err := candle.ParseIntervals(cfg.CandleIntervals)
+	if err != nil {
+		panic(err)
+	}
+	var aggregator *candle.Aggregator
+	if len(intervals) > 0 {
+		aggregator = candle.NewAggregator(repository.NewCandles(db), intervals)
+		go aggregator.Run(ctx, priceHub, cfg.CandleReplay)
+	}
 
 	// optionally run GRPC
 	// commented since Postman can't test it
@@ -60,7 +273,7 @@ func main() {
 		if err != nil {
 			panic(err)
 		}
-		grpcServer := srvGrpc.NewPricesServer(receiver, errors, cfg, pricesRepo)
+		grpcServer := srvGrpc.NewPricesServer(priceHub, cfg).WithProver(prover).WithCandles(aggregator).WithStats(statsService)
 		s := grpc.NewServer()
 		pb.RegisterPricesStreamingServiceServer(s, grpcServer)
 		go func() {
@@ -71,12 +284,80 @@ func main() {
 	*/
 
 	// run ws
-	srv, err := server.NewServer(receiver, errors, cfg, pricesRepo)
+	srv, err := server.NewServer(priceHub, cfg)
 	if err != nil {
 		panic(err)
 	}
+	srv.WithAttester(attester)
+	srv.WithProver(prover)
+	srv.WithElector(elector)
+	srv.WithPrices(pricesRepo)
+	srv.WithCandles(aggregator)
+	srv.WithStats(statsService)
 	err = srv.Run()
 	if err != nil {
 		log.Err(err).Msg("server failed to start")
 	}
 }
+
+// newPriceProvider combines the polling sources of a single asset according to the configured mode.
+func newPriceProvider(cfg *config.Config, sources []provider.Source) client.PriceProvider {
+	if cfg.ProviderMode == "failover" {
+		return provider.NewFailoverProvider(sources, provider.FailoverOptions{
+			Window:         cfg.FailoverWindow,
+			MinSuccessRate: cfg.FailoverMinSuccessRate,
+			MaxLatency:     cfg.FailoverMaxLatency,
+			RecoverAfter:   cfg.FailoverRecoverAfter,
+			ProbeInterval:  cfg.FailoverProbeInterval,
+		})
+	}
+	return provider.NewAggregatorProvider(sources, provider.Consensus(cfg.Aggregation), cfg.MaxDeviation, cfg.MinSources)
+}
+
+// newBroker returns the configured message bus, nil when prices aren't broadcast through one.
+func newBroker(cfg *config.Config) broker.Broker {
+	switch cfg.Broker {
+	case "memory":
+		return broker.NewMemory()
+	case "redis":
+		return broker.NewRedis(cfg.BrokerAddr)
+	}
+	return nil
+}
+
+// instanceID names the instance in leader election.
+func instanceID(cfg *config.Config) string {
+	if cfg.InstanceID != "" {
+		return cfg.InstanceID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err)
+	}
+	return hostname
+}
+
+// applyDecimalSpecs declares the precision and scale configured per currency.
+func applyDecimalSpecs(cfg *config.Config) error {
+	codes := make(map[string]bool)
+	for code := range cfg.CurrencyPrecision {
+		codes[code] = true
+	}
+	for code := range cfg.CurrencyScale {
+		codes[code] = true
+	}
+
+	for code := range codes {
+		spec := model.DefaultDecimalSpec
+		if precision, ok := cfg.CurrencyPrecision[code]; ok {
+			spec.Precision = precision
+		}
+		if scale, ok := cfg.CurrencyScale[code]; ok {
+			spec.Scale = int32(scale)
+		}
+		if err := model.SetDecimalSpec(code, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,6 +20,9 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	PricesStreamingService_GetDataStreaming_FullMethodName = "/prices.PricesStreamingService/GetDataStreaming"
+	PricesStreamingService_GetPriceProof_FullMethodName    = "/prices.PricesStreamingService/GetPriceProof"
+	PricesStreamingService_GetCandles_FullMethodName       = "/prices.PricesStreamingService/GetCandles"
+	PricesStreamingService_GetPriceStats_FullMethodName    = "/prices.PricesStreamingService/GetPriceStats"
 )
 
 // PricesStreamingServiceClient is the client API for PricesStreamingService service.
@@ -28,6 +31,9 @@ const (
 type PricesStreamingServiceClient interface {
 	// unary
 	GetDataStreaming(ctx context.Context, in *PricesRequest, opts ...grpc.CallOption) (PricesStreamingService_GetDataStreamingClient, error)
+	GetPriceProof(ctx context.Context, in *ProofRequest, opts ...grpc.CallOption) (*ProofResponse, error)
+	GetCandles(ctx context.Context, in *CandlesRequest, opts ...grpc.CallOption) (*CandlesResponse, error)
+	GetPriceStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
 }
 
 type pricesStreamingServiceClient struct {
@@ -70,12 +76,42 @@ func (x *pricesStreamingServiceGetDataStreamingClient) Recv() (*PricesResponse,
 	return m, nil
 }
 
+func (c *pricesStreamingServiceClient) GetPriceProof(ctx context.Context, in *ProofRequest, opts ...grpc.CallOption) (*ProofResponse, error) {
+	out := new(ProofResponse)
+	err := c.cc.Invoke(ctx, PricesStreamingService_GetPriceProof_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pricesStreamingServiceClient) GetCandles(ctx context.Context, in *CandlesRequest, opts ...grpc.CallOption) (*CandlesResponse, error) {
+	out := new(CandlesResponse)
+	err := c.cc.Invoke(ctx, PricesStreamingService_GetCandles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pricesStreamingServiceClient) GetPriceStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, PricesStreamingService_GetPriceStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PricesStreamingServiceServer is the server API for PricesStreamingService service.
 // All implementations must embed UnimplementedPricesStreamingServiceServer
 // for forward compatibility
 type PricesStreamingServiceServer interface {
 	// unary
 	GetDataStreaming(*PricesRequest, PricesStreamingService_GetDataStreamingServer) error
+	GetPriceProof(context.Context, *ProofRequest) (*ProofResponse, error)
+	GetCandles(context.Context, *CandlesRequest) (*CandlesResponse, error)
+	GetPriceStats(context.Context, *StatsRequest) (*StatsResponse, error)
 	mustEmbedUnimplementedPricesStreamingServiceServer()
 }
 
@@ -86,6 +122,15 @@ type UnimplementedPricesStreamingServiceServer struct {
 func (UnimplementedPricesStreamingServiceServer) GetDataStreaming(*PricesRequest, PricesStreamingService_GetDataStreamingServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetDataStreaming not implemented")
 }
+func (UnimplementedPricesStreamingServiceServer) GetPriceProof(context.Context, *ProofRequest) (*ProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceProof not implemented")
+}
+func (UnimplementedPricesStreamingServiceServer) GetCandles(context.Context, *CandlesRequest) (*CandlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandles not implemented")
+}
+func (UnimplementedPricesStreamingServiceServer) GetPriceStats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceStats not implemented")
+}
 func (UnimplementedPricesStreamingServiceServer) mustEmbedUnimplementedPricesStreamingServiceServer() {
 }
 
@@ -121,13 +166,80 @@ func (x *pricesStreamingServiceGetDataStreamingServer) Send(m *PricesResponse) e
 	return x.ServerStream.SendMsg(m)
 }
 
+func _PricesStreamingService_GetPriceProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricesStreamingServiceServer).GetPriceProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricesStreamingService_GetPriceProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricesStreamingServiceServer).GetPriceProof(ctx, req.(*ProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PricesStreamingService_GetCandles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CandlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricesStreamingServiceServer).GetCandles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricesStreamingService_GetCandles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricesStreamingServiceServer).GetCandles(ctx, req.(*CandlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PricesStreamingService_GetPriceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricesStreamingServiceServer).GetPriceStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricesStreamingService_GetPriceStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricesStreamingServiceServer).GetPriceStats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PricesStreamingService_ServiceDesc is the grpc.ServiceDesc for PricesStreamingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var PricesStreamingService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "prices.PricesStreamingService",
 	HandlerType: (*PricesStreamingServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPriceProof",
+			Handler:    _PricesStreamingService_GetPriceProof_Handler,
+		},
+		{
+			MethodName: "GetCandles",
+			Handler:    _PricesStreamingService_GetCandles_Handler,
+		},
+		{
+			MethodName: "GetPriceStats",
+			Handler:    _PricesStreamingService_GetPriceStats_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "GetDataStreaming",